@@ -1,5 +1,9 @@
 package fsm
 
+import (
+	"time"
+)
+
 // FromStep Step marker interfaces to enforce the correct order of method calls
 type FromStep interface{}
 type ToStep interface{}
@@ -43,21 +47,68 @@ type ToInterface[S comparable, E comparable, P any] interface {
 }
 
 // OnInterface is the interface for specifying the triggering event of a transition
+// The condition is optional, a transition without one is always taken
 type OnInterface[S comparable, E comparable, P any] interface {
 	// When specifies the condition for the transition
 	When(condition Condition[P]) WhenInterface[S, E, P]
 
 	// WhenFunc specifies a function as the condition for the transition
 	WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P]
+
+	// Perform specifies the action to execute during the transition
+	Perform(action Action[S, E, P]) PerformInterface[S, E, P]
+
+	// PerformFunc specifies a function as the action to execute during the transition
+	PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P]
 }
 
 // WhenInterface is the interface for specifying the condition of a transition
+// Further conditions can be added, the transition is taken only when all of them are satisfied
 type WhenInterface[S comparable, E comparable, P any] interface {
+	// When adds a condition that must be satisfied as well
+	When(condition Condition[P]) WhenInterface[S, E, P]
+
+	// WhenFunc adds a function as a condition that must be satisfied as well
+	WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P]
+
 	// Perform specifies the action to execute during the transition
-	Perform(action Action[S, E, P])
+	Perform(action Action[S, E, P]) PerformInterface[S, E, P]
 
 	// PerformFunc specifies a function as the action to execute during the transition
-	PerformFunc(actionFunc func(from, to S, event E, payload P) error)
+	PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P]
+}
+
+// PerformInterface is the interface for configuring transitions after their action was specified
+type PerformInterface[S comparable, E comparable, P any] interface {
+	// Name names the transitions, the name appears in transition descriptors and transition contexts
+	Name(name string) PerformInterface[S, E, P]
+
+	// Metadata attaches a key-value pair to the transitions
+	Metadata(key, value string) PerformInterface[S, E, P]
+
+	// Compensate specifies the action that undoes the transitions when a later branch of the same firing fails
+	Compensate(action Action[S, E, P]) PerformInterface[S, E, P]
+
+	// CompensateFunc specifies a function that undoes the transitions when a later branch of the same firing fails
+	CompensateFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P]
+
+	// Then specifies an action to execute after the previous ones succeeded
+	Then(action Action[S, E, P]) PerformInterface[S, E, P]
+
+	// ThenFunc specifies a function to execute after the previous actions succeeded
+	ThenFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P]
+
+	// Retry retries the action of the transitions when it fails
+	Retry(policy RetryPolicy) PerformInterface[S, E, P]
+
+	// Timeout limits how long each attempt of the action of the transitions may run
+	Timeout(timeout time.Duration) PerformInterface[S, E, P]
+
+	// OnError moves the machine to a fallback state instead of failing when the action of the transitions fails
+	OnError(state S, match ...func(err error) bool) PerformInterface[S, E, P]
+
+	// Priority sets the order in which the transitions are evaluated against others for the same event
+	Priority(priority int) PerformInterface[S, E, P]
 }
 
 // InternalTransitionBuilderInterface is the interface for building internal transitions
@@ -102,6 +153,7 @@ var (
 	_ ToInterface[string, string, any]                                = (*OnTransitionBuilder[string, string, any, OnStep])(nil)
 	_ OnInterface[string, string, any]                                = (*OnTransitionBuilder[string, string, any, WhenStep])(nil)
 	_ WhenInterface[string, string, any]                              = (*OnTransitionBuilder[string, string, any, PerformStep])(nil)
+	_ PerformInterface[string, string, any]                           = (*PerformBuilder[string, string, any])(nil)
 )
 
 // StateMachineBuilder builds state machines with a fluent API
@@ -160,6 +212,159 @@ func (b *StateMachineBuilder[S, E, P]) ExternalParallelTransition() ExternalPara
 	}
 }
 
+// WithMetrics reports firings, condition evaluations and action executions to a metrics collector
+// Parameters:
+//
+//	metrics: The metrics collector, see InMemoryMetrics for a built-in implementation
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithMetrics(metrics Metrics) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.metrics = metrics
+	return b
+}
+
+// WithTracer opens a span for every firing with child spans for condition evaluations and actions
+// Parameters:
+//
+//	tracer: The tracer, see RecordingTracer for a built-in implementation
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithTracer(tracer Tracer) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.tracer = tracer
+	return b
+}
+
+// WithLogger emits a structured record for every firing
+// Parameters:
+//
+//	logger: The logger, see NewSlogLogger for a log/slog adapter
+//	policy: Optional level policy, DefaultLevelPolicy if omitted
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithLogger(logger Logger, policy ...LevelPolicy) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.logger = logger
+	if len(policy) > 0 {
+		b.stateMachine.logLevels = policy[0]
+	}
+	return b
+}
+
+// WithPanicRecovery converts panics in conditions and actions into a *PanicError returned from the firing call
+// Parameters:
+//
+//	handler: Optional handler notified of every recovered panic
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithPanicRecovery(handler ...PanicHandler) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.recoverPanics = true
+	if len(handler) > 0 {
+		b.stateMachine.panicHandler = handler[0]
+	}
+	return b
+}
+
+// WithClock sets the clock handed to contextual conditions and actions, SystemClock by default
+// Parameters:
+//
+//	clock: The clock to use
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithClock(clock Clock) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.clock = clock
+	return b
+}
+
+// WithActionTimeout limits how long each action attempt may run, for transitions without their own Timeout
+// An action exceeding it is abandoned and the firing fails with a *TransitionError matching ErrActionTimeout;
+// contextual actions see their context cancelled, plain actions keep running in the background
+// Timed actions run in their own goroutine; without WithPanicRecovery their panics are raised again
+// on the goroutine that fired the event, except for actions that were already abandoned
+// Parameters:
+//
+//	timeout: The limit of each action attempt, zero means no limit
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithActionTimeout(timeout time.Duration) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.defaultTimeout = timeout
+	return b
+}
+
+// WithStrictMode makes FireEvent fail with ErrAmbiguousTransition instead of taking the first satisfied transition
+// when another transition of the same priority is satisfied for the same payload
+// FireParallelEvent is unaffected, taking every satisfied transition is what it is for,
+// and the branches of a parallel transition never contend with each other or with other transitions
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithStrictMode() *StateMachineBuilder[S, E, P] {
+	b.stateMachine.strict = true
+	return b
+}
+
+// WithUnhandledEventPolicy decides what firing an event does when the source state declares no transition for it,
+// instead of failing with ErrTransitionNotFound
+// Explain and Simulate describe the declared transitions only and keep reporting ErrTransitionNotFound
+// Parameters:
+//
+//	policy: UnhandledEventError, UnhandledEventIgnore or UnhandledEventFallback
+//	handler: The fallback handler, required by UnhandledEventFallback, Build fails with ErrMissingFallbackHandler without it
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithUnhandledEventPolicy(policy UnhandledEventPolicy, handler ...UnhandledEventHandler[S, E, P]) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.unhandledEvents = policy
+	if len(handler) > 0 {
+		b.stateMachine.onUnhandled = handler[0]
+	}
+	return b
+}
+
+// Ignore keeps the machine in the given state without error when one of the events is fired there,
+// whatever the unhandled event policy, for example to absorb duplicate deliveries of an event already processed
+// Events for which the state declares transitions are still handled by them
+// Parameters:
+//
+//	state: The state ignoring the events
+//	events: The events to ignore
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) Ignore(state S, events ...E) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.GetState(state).ignore(events...)
+	return b
+}
+
+// WithParallelExecution runs the branch actions of FireParallelEvent concurrently instead of one after another
+// All branches run to completion unless FailFast is set, and failures are returned as a *ParallelError
+// Listeners, conditions and actions of parallel transitions must then be safe for concurrent use
+// Without WithPanicRecovery, a panic in a branch is raised again on the goroutine that fired the event
+// once every branch has finished, so recover in the caller or in middleware still sees it
+// Parameters:
+//
+//	options: Concurrency limit and fail-fast behavior
+//
+// Returns:
+//
+//	The builder for method chaining
+func (b *StateMachineBuilder[S, E, P]) WithParallelExecution(options ParallelOptions) *StateMachineBuilder[S, E, P] {
+	b.stateMachine.parallelOptions = &options
+	return b
+}
+
 // Build finalizes the state machine with the given ID
 // Parameters:
 //
@@ -170,6 +375,9 @@ func (b *StateMachineBuilder[S, E, P]) ExternalParallelTransition() ExternalPara
 //	The built state machine and possible error
 func (b *StateMachineBuilder[S, E, P]) Build(machineId string) (StateMachine[S, E, P], error) {
 	b.stateMachine.id = machineId
+	if b.stateMachine.unhandledEvents == UnhandledEventFallback && b.stateMachine.onUnhandled == nil {
+		return nil, ErrMissingFallbackHandler
+	}
 	b.stateMachine.SetReady(true)
 
 	// Register the state machine in a factory
@@ -259,7 +467,7 @@ func (b *ParallelFromBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 	return (*ParallelFromBuilder[S, E, P, WhenStep])(b)
 }
 
-// When specifies the condition for all transitions
+// When adds a condition for all transitions, which are taken only when all their conditions are satisfied
 // Parameters:
 //
 //	condition: The condition that must be satisfied for the transitions to occur
@@ -268,11 +476,11 @@ func (b *ParallelFromBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 //
 //	The parallel from builder for method chaining
 func (b *ParallelFromBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInterface[S, E, P] {
-	b.condition = condition
+	b.condition = addGuard(b.condition, condition)
 	return (*ParallelFromBuilder[S, E, P, PerformStep])(b)
 }
 
-// WhenFunc specifies a function as the condition for all transitions
+// WhenFunc adds a function as a condition for all transitions
 // Parameters:
 //
 //	conditionFunc: The function that must return true for the transitions to occur
@@ -281,7 +489,7 @@ func (b *ParallelFromBuilder[S, E, P, Next]) When(condition Condition[P]) WhenIn
 //
 //	The parallel from builder for method chaining
 func (b *ParallelFromBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P] {
-	b.condition = ConditionFunc[P](conditionFunc)
+	b.condition = addGuard[P](b.condition, ConditionFunc[P](conditionFunc))
 	return (*ParallelFromBuilder[S, E, P, PerformStep])(b)
 }
 
@@ -289,38 +497,39 @@ func (b *ParallelFromBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload
 // Parameters:
 //
 //	action: The action to execute when the transitions occur
-func (b *ParallelFromBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *ParallelFromBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) PerformInterface[S, E, P] {
 	b.action = action
 
 	// Get or create source state
 	sourceState := b.stateMachine.GetState(b.sourceId)
 
 	// Create transitions to all target states
+	transitions := make([]*Transition[S, E, P], 0, len(b.targetIds))
 	for _, targetId := range b.targetIds {
 		targetState := b.stateMachine.GetState(targetId)
 		transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
 		transition.Condition = b.condition
 		transition.Action = b.action
+		transition.parallel = true
+		transitions = append(transitions, transition)
 	}
+	return &PerformBuilder[S, E, P]{stateMachine: b.stateMachine, transitions: transitions}
 }
 
 // PerformFunc specifies a function as the action to execute during all transitions
 // Parameters:
 //
 //	actionFunc: The function to execute when the transitions occur
-func (b *ParallelFromBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) {
-	b.action = ActionFunc[S, E, P](actionFunc)
-
-	// Get or create source state
-	sourceState := b.stateMachine.GetState(b.sourceId)
-
-	// Create transitions to all target states
-	for _, targetId := range b.targetIds {
-		targetState := b.stateMachine.GetState(targetId)
-		transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
-		transition.Condition = b.condition
-		transition.Action = b.action
-	}
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *ParallelFromBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Perform(ActionFunc[S, E, P](actionFunc))
 }
 
 // TransitionBuilder builds individual transitions
@@ -373,7 +582,7 @@ func (b *TransitionBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 	return (*TransitionBuilder[S, E, P, WhenStep])(b)
 }
 
-// When specifies the condition for the transition
+// When adds a condition for the transition, which is taken only when all its conditions are satisfied
 // Parameters:
 //
 //	condition: The condition that must be satisfied for the transition to occur
@@ -382,11 +591,11 @@ func (b *TransitionBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 //
 //	The transition builder for method chaining
 func (b *TransitionBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInterface[S, E, P] {
-	b.condition = condition
+	b.condition = addGuard(b.condition, condition)
 	return (*TransitionBuilder[S, E, P, PerformStep])(b)
 }
 
-// WhenFunc specifies a function as the condition for the transition
+// WhenFunc adds a function as a condition for the transition
 // Parameters:
 //
 //	conditionFunc: The function that must return true for the transition to occur
@@ -395,7 +604,7 @@ func (b *TransitionBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInte
 //
 //	The transition builder for method chaining
 func (b *TransitionBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P] {
-	b.condition = ConditionFunc[P](conditionFunc)
+	b.condition = addGuard[P](b.condition, ConditionFunc[P](conditionFunc))
 	return (*TransitionBuilder[S, E, P, PerformStep])(b)
 }
 
@@ -403,7 +612,11 @@ func (b *TransitionBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P
 // Parameters:
 //
 //	action: The action to execute when the transition occurs
-func (b *TransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *TransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) PerformInterface[S, E, P] {
 	b.action = action
 
 	// Get or create states
@@ -414,23 +627,19 @@ func (b *TransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
 	transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
 	transition.Condition = b.condition
 	transition.Action = b.action
+	return &PerformBuilder[S, E, P]{stateMachine: b.stateMachine, transitions: []*Transition[S, E, P]{transition}}
 }
 
 // PerformFunc specifies a function as the action to execute during the transition
 // Parameters:
 //
 //	actionFunc: The function to execute when the transition occurs
-func (b *TransitionBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) {
-	b.action = ActionFunc[S, E, P](actionFunc)
-
-	// Get or create states
-	sourceState := b.stateMachine.GetState(b.sourceId)
-	targetState := b.stateMachine.GetState(b.targetId)
-
-	// Create transition
-	transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
-	transition.Condition = b.condition
-	transition.Action = b.action
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *TransitionBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Perform(ActionFunc[S, E, P](actionFunc))
 }
 
 // FromBuilder builds the "from" part of multiple transitions
@@ -470,7 +679,7 @@ func (b *FromBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 	return (*FromBuilder[S, E, P, WhenStep])(b)
 }
 
-// When specifies the condition for all transitions
+// When adds a condition for all transitions, which are taken only when all their conditions are satisfied
 // Parameters:
 //
 //	condition: The condition that must be satisfied for the transitions to occur
@@ -479,11 +688,11 @@ func (b *FromBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 //
 //	The from builder for method chaining
 func (b *FromBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInterface[S, E, P] {
-	b.condition = condition
+	b.condition = addGuard(b.condition, condition)
 	return (*FromBuilder[S, E, P, PerformStep])(b)
 }
 
-// WhenFunc specifies a function as the condition for all transitions
+// WhenFunc adds a function as a condition for all transitions
 // Parameters:
 //
 //	conditionFunc: The function that must return true for the transitions to occur
@@ -492,7 +701,7 @@ func (b *FromBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInterface[
 //
 //	The from builder for method chaining
 func (b *FromBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P] {
-	b.condition = ConditionFunc[P](conditionFunc)
+	b.condition = addGuard[P](b.condition, ConditionFunc[P](conditionFunc))
 	return (*FromBuilder[S, E, P, PerformStep])(b)
 }
 
@@ -500,38 +709,38 @@ func (b *FromBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P) bool
 // Parameters:
 //
 //	action: The action to execute when the transitions occur
-func (b *FromBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *FromBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) PerformInterface[S, E, P] {
 	b.action = action
 
 	// Get or create target state
 	targetState := b.stateMachine.GetState(b.targetId)
 
 	// Create transitions from all source states
+	transitions := make([]*Transition[S, E, P], 0, len(b.sourceIds))
 	for _, sourceId := range b.sourceIds {
 		sourceState := b.stateMachine.GetState(sourceId)
 		transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
 		transition.Condition = b.condition
 		transition.Action = b.action
+		transitions = append(transitions, transition)
 	}
+	return &PerformBuilder[S, E, P]{stateMachine: b.stateMachine, transitions: transitions}
 }
 
 // PerformFunc specifies a function as the action to execute during all transitions
 // Parameters:
 //
 //	actionFunc: The function to execute when the transitions occur
-func (b *FromBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) {
-	b.action = ActionFunc[S, E, P](actionFunc)
-
-	// Get or create target state
-	targetState := b.stateMachine.GetState(b.targetId)
-
-	// Create transitions from all source states
-	for _, sourceId := range b.sourceIds {
-		sourceState := b.stateMachine.GetState(sourceId)
-		transition := sourceState.AddTransition(b.event, targetState, b.transitionType)
-		transition.Condition = b.condition
-		transition.Action = b.action
-	}
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *FromBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Perform(ActionFunc[S, E, P](actionFunc))
 }
 
 // InternalTransitionBuilder builds internal transitions
@@ -578,7 +787,7 @@ func (b *OnTransitionBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 	return (*OnTransitionBuilder[S, E, P, WhenStep])(b)
 }
 
-// When specifies the condition for the transition
+// When adds a condition for the transition, which is taken only when all its conditions are satisfied
 // Parameters:
 //
 //	condition: The condition that must be satisfied for the transition to occur
@@ -587,11 +796,11 @@ func (b *OnTransitionBuilder[S, E, P, Next]) On(event E) OnInterface[S, E, P] {
 //
 //	The on transition builder for method chaining
 func (b *OnTransitionBuilder[S, E, P, Next]) When(condition Condition[P]) WhenInterface[S, E, P] {
-	b.condition = condition
+	b.condition = addGuard(b.condition, condition)
 	return (*OnTransitionBuilder[S, E, P, PerformStep])(b)
 }
 
-// WhenFunc specifies a function as the condition for the transition
+// WhenFunc adds a function as a condition for the transition
 // Parameters:
 //
 //	conditionFunc: The function that must return true for the transition to occur
@@ -600,7 +809,7 @@ func (b *OnTransitionBuilder[S, E, P, Next]) When(condition Condition[P]) WhenIn
 //
 //	The on transition builder for method chaining
 func (b *OnTransitionBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload P) bool) WhenInterface[S, E, P] {
-	b.condition = ConditionFunc[P](conditionFunc)
+	b.condition = addGuard[P](b.condition, ConditionFunc[P](conditionFunc))
 	return (*OnTransitionBuilder[S, E, P, PerformStep])(b)
 }
 
@@ -608,7 +817,11 @@ func (b *OnTransitionBuilder[S, E, P, Next]) WhenFunc(conditionFunc func(payload
 // Parameters:
 //
 //	action: The action to execute when the transition occurs
-func (b *OnTransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *OnTransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) PerformInterface[S, E, P] {
 	b.action = action
 
 	// Get or create state
@@ -618,20 +831,192 @@ func (b *OnTransitionBuilder[S, E, P, Next]) Perform(action Action[S, E, P]) {
 	transition := state.AddTransition(b.event, state, b.transitionType)
 	transition.Condition = b.condition
 	transition.Action = b.action
+	return &PerformBuilder[S, E, P]{stateMachine: b.stateMachine, transitions: []*Transition[S, E, P]{transition}}
 }
 
 // PerformFunc specifies a function as the action to execute during the transition
 // Parameters:
 //
 //	actionFunc: The function to execute when the transition occurs
-func (b *OnTransitionBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) {
-	b.action = ActionFunc[S, E, P](actionFunc)
+//
+// Returns:
+//
+//	The perform builder for naming and annotating the created transitions
+func (b *OnTransitionBuilder[S, E, P, Next]) PerformFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Perform(ActionFunc[S, E, P](actionFunc))
+}
 
-	// Get or create state
-	state := b.stateMachine.GetState(b.stateId)
+// PerformBuilder configures the transitions created by Perform or PerformFunc
+type PerformBuilder[S comparable, E comparable, P any] struct {
+	stateMachine *StateMachineImpl[S, E, P]
+	transitions  []*Transition[S, E, P]
+}
 
-	// Create internal transition
-	transition := state.AddTransition(b.event, state, b.transitionType)
-	transition.Condition = b.condition
-	transition.Action = b.action
+// Name names the transitions
+// Parameters:
+//
+//	name: The transition name, reported in TransitionInfo and TransitionContext
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Name(name string) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Name = name
+	}
+	return b
+}
+
+// Metadata attaches a key-value pair to the transitions
+// Parameters:
+//
+//	key: The metadata key
+//	value: The metadata value
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Metadata(key, value string) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		if transition.Metadata == nil {
+			transition.Metadata = make(map[string]string)
+		}
+		transition.Metadata[key] = value
+	}
+	return b
+}
+
+// Compensate specifies the action that undoes the transitions
+// Parameters:
+//
+//	action: The compensation, run in reverse order for completed branches when a later branch fails,
+//	and when a later action of the transition's chain fails after earlier ones succeeded
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Compensate(action Action[S, E, P]) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Compensation = action
+	}
+	return b
+}
+
+// CompensateFunc specifies a function that undoes the transitions
+// Parameters:
+//
+//	actionFunc: The compensation, run in reverse order for completed branches when a later branch fails
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) CompensateFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Compensate(ActionFunc[S, E, P](actionFunc))
+}
+
+// Retry retries the action of the transitions when it fails
+// Parameters:
+//
+//	policy: How many attempts to make, how long to wait between them and which errors to retry
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Retry(policy RetryPolicy) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Retry = &policy
+	}
+	return b
+}
+
+// Timeout limits how long each attempt of the action of the transitions may run, overriding WithActionTimeout
+// Parameters:
+//
+//	timeout: The limit of each action attempt
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Timeout(timeout time.Duration) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Timeout = timeout
+	}
+	return b
+}
+
+// Priority sets the order in which the transitions are evaluated against others for the same state and event
+// Higher priorities are evaluated first and transitions of equal priority in declaration order, the default is 0
+// Parameters:
+//
+//	priority: The priority of the transitions
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Priority(priority int) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Priority = priority
+		transition.Source.sortByPriority(transition.Event)
+	}
+	return b
+}
+
+// OnError moves the machine to a fallback state instead of failing when the action of the transitions fails
+// Routes are tried in the order they were added, the first one matching the failure is taken
+// Parameters:
+//
+//	state: The fallback state, for example PaymentFailed
+//	match: Optional matcher selecting the failures handled by the route, every failure if omitted
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) OnError(state S, match ...func(err error) bool) PerformInterface[S, E, P] {
+	fallbackState := b.stateMachine.GetState(state)
+	for _, transition := range b.transitions {
+		route := ErrorRoute[S, E, P]{
+			Transition: &Transition[S, E, P]{
+				Source:    transition.Source,
+				Target:    fallbackState,
+				Event:     transition.Event,
+				TransType: External,
+			},
+		}
+		if len(match) > 0 {
+			route.Match = match[0]
+		}
+		transition.ErrorRoutes = append(transition.ErrorRoutes, route)
+	}
+	return b
+}
+
+// Then specifies an action to execute after the previous actions of the transitions succeeded
+// The actions run in declaration order and the first failure stops the chain and fails the transition.
+// Timeout limits each attempt of the chain, and Retry resumes the chain at the action that failed
+// without running the actions that succeeded again. When the chain fails after some actions succeeded,
+// the compensation of the transition runs unless an OnError route takes the failure
+// Parameters:
+//
+//	action: The action to execute next
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) Then(action Action[S, E, P]) PerformInterface[S, E, P] {
+	for _, transition := range b.transitions {
+		transition.Action = addAction(transition.Action, action)
+	}
+	return b
+}
+
+// ThenFunc specifies a function to execute after the previous actions of the transitions succeeded
+// Parameters:
+//
+//	actionFunc: The function to execute next
+//
+// Returns:
+//
+//	The perform builder for method chaining
+func (b *PerformBuilder[S, E, P]) ThenFunc(actionFunc func(from, to S, event E, payload P) error) PerformInterface[S, E, P] {
+	return b.Then(ActionFunc[S, E, P](actionFunc))
 }
@@ -0,0 +1,98 @@
+package fsm
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// addGuard combines the conditions declared so far with another one
+// Conditions of several When calls are combined with And and must all be satisfied
+func addGuard[P any](existing Condition[P], condition Condition[P]) Condition[P] {
+	switch declared := existing.(type) {
+	case nil:
+		return condition
+	case allCondition[P]:
+		return append(declared[:len(declared):len(declared)], condition)
+	default:
+		return allCondition[P]{existing, condition}
+	}
+}
+
+// actionChain is the action of a transition declared with Perform followed by Then calls
+// The actions run in declaration order and the first failure stops the chain
+type actionChain[S comparable, E comparable, P any] []Action[S, E, P]
+
+// Execute implements Action interface
+func (c actionChain[S, E, P]) Execute(from, to S, event E, payload P) error {
+	for _, action := range c {
+		if err := action.Execute(from, to, event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteContext implements ContextualAction interface, passing the context to contextual actions
+func (c actionChain[S, E, P]) ExecuteContext(tc *TransitionContext[S, E], payload P) error {
+	for _, action := range c {
+		var err error
+		if contextual, ok := action.(ContextualAction[S, E, P]); ok {
+			err = contextual.ExecuteContext(tc, payload)
+		} else {
+			err = action.Execute(tc.Transition.Source, tc.Transition.Target, tc.Transition.Event, payload)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// actionRun tracks the execution of a transition's action across retry attempts
+type actionRun struct {
+	completed int32                 // chained actions that succeeded, later attempts resume after them
+	abandoned <-chan attemptOutcome // reports the end of the last attempt when it was abandoned still running
+}
+
+// partial reports whether some chained actions succeeded, so their effects remain after a failure
+func (r *actionRun) partial() bool {
+	return atomic.LoadInt32(&r.completed) > 0
+}
+
+// executeChain runs the actions of a chain, starting after those that succeeded in earlier attempts
+// The chain stops before the next action once ctx is done, so an abandoned attempt does not run ahead of its retry
+func (sm *StateMachineImpl[S, E, P]) executeChain(f *firing[S, E], ctx context.Context, transition *Transition[S, E, P], chain actionChain[S, E, P], payload P, attempt int, run *actionRun) error {
+	start := int(atomic.LoadInt32(&run.completed))
+	for i := start; i < len(chain); i++ {
+		if i > start && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var err error
+		if contextual, ok := chain[i].(ContextualAction[S, E, P]); ok {
+			tc := sm.transitionContext(f, transition)
+			tc.Context = ctx
+			tc.Attempt = attempt
+			err = contextual.ExecuteContext(tc, payload)
+		} else {
+			err = chain[i].Execute(transition.Source.GetID(), transition.Target.GetID(), transition.Event, payload)
+		}
+		if err != nil {
+			return err
+		}
+		atomic.CompareAndSwapInt32(&run.completed, int32(i), int32(i+1))
+	}
+	return nil
+}
+
+// addAction appends an action to the action of a transition
+func addAction[S comparable, E comparable, P any](existing Action[S, E, P], action Action[S, E, P]) Action[S, E, P] {
+	switch declared := existing.(type) {
+	case nil:
+		return action
+	case actionChain[S, E, P]:
+		return append(declared[:len(declared):len(declared)], action)
+	default:
+		return actionChain[S, E, P]{existing, action}
+	}
+}
@@ -0,0 +1,196 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestOptionalAndMultipleGuards tests transitions without a guard and with several guards
+func TestOptionalAndMultipleGuards(t *testing.T) {
+	var evaluated []string
+	guard := func(name string, result bool) func(payload testPayload) bool {
+		return func(payload testPayload) bool {
+			evaluated = append(evaluated, name)
+			return result && payload.Value != name
+		}
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		Perform(&noopAction{})
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event1).
+		WhenFunc(guard("first", true)).
+		WhenFunc(guard("second", true)).
+		When(ContextualConditionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) bool {
+				evaluated = append(evaluated, "third")
+				return tc.MachineId == "GuardsTest"
+			})).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("GuardsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if target, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || target != StateB {
+		t.Errorf("Expected a transition without guard to be taken, got %v: %v", target, err)
+	}
+
+	if target, err := sm.FireEvent(StateB, Event1, testPayload{}); err != nil || target != StateC {
+		t.Errorf("Expected C when all guards pass, got %v: %v", target, err)
+	}
+	if expected := []string{"first", "second", "third"}; !reflect.DeepEqual(evaluated, expected) {
+		t.Errorf("Expected guards %v to be evaluated, got %v", expected, evaluated)
+	}
+
+	evaluated = nil
+	if _, err := sm.FireEvent(StateB, Event1, testPayload{Value: "first"}); !errors.Is(err, ErrConditionNotMet) {
+		t.Errorf("Expected ErrConditionNotMet when a guard fails, got %v", err)
+	}
+	if expected := []string{"first"}; !reflect.DeepEqual(evaluated, expected) {
+		t.Errorf("Expected evaluation to stop at the failing guard, got %v", evaluated)
+	}
+}
+
+// TestActionChain tests that chained actions run in order and stop at the first failure
+func TestActionChain(t *testing.T) {
+	var calls []string
+	step := func(name string) func(from, to testState, event testEvent, payload testPayload) error {
+		return func(from, to testState, event testEvent, payload testPayload) error {
+			calls = append(calls, name)
+			if payload.Value == name {
+				return errors.New(name + " failed")
+			}
+			return nil
+		}
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		PerformFunc(step("reserve")).
+		Then(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				calls = append(calls, "charge "+string(tc.Transition.Target))
+				if payload.Value == "charge" {
+					return errors.New("charge failed")
+				}
+				return nil
+			})).
+		ThenFunc(step("notify"))
+
+	sm, err := builder.Build("ActionChainTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if target, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || target != StateB {
+		t.Errorf("Expected B, got %v: %v", target, err)
+	}
+	if expected := []string{"reserve", "charge B", "notify"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+
+	calls = nil
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{Value: "charge"}); !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected ErrActionExecutionFailed, got %v", err)
+	}
+	if expected := []string{"reserve", "charge B"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected the chain to stop at the failing action, got %v", calls)
+	}
+}
+
+// TestActionChainRetry tests that retries resume at the failing action
+func TestActionChainRetry(t *testing.T) {
+	var calls []string
+	failures := 1
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			calls = append(calls, "reserve")
+			return nil
+		}).
+		ThenFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			calls = append(calls, "book")
+			if failures > 0 {
+				failures--
+				return errors.New("courier unavailable")
+			}
+			return nil
+		}).
+		Retry(RetryPolicy{MaxAttempts: 2})
+
+	sm, err := builder.Build("ActionChainRetryTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if target, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || target != StateB {
+		t.Errorf("Expected B after a retry, got %v: %v", target, err)
+	}
+	if expected := []string{"reserve", "book", "book"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected the retry to resume at the failed action, got %v", calls)
+	}
+}
+
+// TestActionChainCompensation tests that a chain failing after a partial run is compensated
+func TestActionChainCompensation(t *testing.T) {
+	var calls []string
+	action := func(name string) func(from, to testState, event testEvent, payload testPayload) error {
+		return func(from, to testState, event testEvent, payload testPayload) error {
+			calls = append(calls, name)
+			if payload.Value == name {
+				return errors.New(name + " failed")
+			}
+			return nil
+		}
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		PerformFunc(action("reserve")).
+		ThenFunc(action("book")).
+		CompensateFunc(action("release")).
+		Retry(RetryPolicy{MaxAttempts: 2})
+
+	sm, err := builder.Build("ActionChainCompensationTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, err = sm.FireEvent(StateA, Event1, testPayload{Value: "book"})
+	var compensationErr *CompensationError[testState]
+	if !errors.As(err, &compensationErr) || !errors.Is(err, ErrActionExecutionFailed) {
+		t.Fatalf("Expected a *CompensationError matching ErrActionExecutionFailed, got %v", err)
+	}
+	if !reflect.DeepEqual(compensationErr.Compensated, []testState{StateB}) {
+		t.Errorf("Expected the transition to be compensated, got %v", compensationErr.Compensated)
+	}
+	if expected := []string{"reserve", "book", "book", "release"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected the partial run to be compensated once retries were exhausted, got %v", calls)
+	}
+
+	calls = nil
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{Value: "reserve"}); !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected ErrActionExecutionFailed, got %v", err)
+	}
+	if expected := []string{"reserve", "reserve"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected no compensation when no action succeeded, got %v", calls)
+	}
+}
@@ -0,0 +1,213 @@
+// Command fsm validates, renders and simulates state machine definitions
+//
+// Usage:
+//
+//	fsm validate <definition>
+//	fsm render [--format=plantuml|mermaid|table|dot] <definition>
+//	fsm paths --from STATE --to STATE <definition>
+//	fsm simulate [--from STATE] <definition>
+//
+// States and events may be given either by name or by value.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `usage:
+  fsm validate <definition>
+  fsm render [--format=plantuml|mermaid|table|dot] <definition>
+  fsm paths --from STATE --to STATE <definition>
+  fsm simulate [--from STATE] <definition>
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run dispatches a subcommand and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "validate":
+		err = runValidate(args[1:], stdout, stderr)
+	case "render":
+		err = runRender(args[1:], stdout, stderr)
+	case "paths":
+		err = runPaths(args[1:], stdout, stderr)
+	case "simulate":
+		err = runSimulate(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "fsm: unknown command %q\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "fsm %s: %v\n%s", args[0], err, usage)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "fsm %s: %v\n", args[0], err)
+		return exitError
+	}
+}
+
+// usageError reports invalid command-line arguments
+type usageError string
+
+// Error implements the error interface
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// newFlagSet creates a flag set that reports parse errors instead of exiting
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
+
+// parseDefinitionArgs parses flags and loads the single positional definition file
+func parseDefinitionArgs(flags *flag.FlagSet, args []string) (*fsm.Definition, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, usageError(err.Error())
+	}
+	if flags.NArg() != 1 {
+		return nil, usageError("expected exactly one definition file")
+	}
+	return fsm.LoadDefinition(flags.Arg(0))
+}
+
+// runValidate checks a definition and lists every problem found
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("validate", stderr)
+	definition, err := parseDefinitionArgs(flags, args)
+
+	var definitionErr *fsm.DefinitionError
+	if errors.As(err, &definitionErr) {
+		for _, problem := range definitionErr.Problems {
+			fmt.Fprintf(stdout, "%s: %s\n", flags.Arg(0), problem)
+		}
+		return fmt.Errorf("%d problem(s) found", len(definitionErr.Problems))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s: ok (%d states, %d events, %d transitions)\n",
+		flags.Arg(0), len(definition.States), len(definition.Events), len(definition.Transitions))
+	return nil
+}
+
+// renderFormats maps --format values to diagram formats
+var renderFormats = map[string]fsm.DiagramFormat{
+	"plantuml":  fsm.PlantUML,
+	"mermaid":   fsm.MarkdownStateDiagram,
+	"flowchart": fsm.MarkdownFlowchart,
+	"table":     fsm.MarkdownTable,
+	"dot":       fsm.Graphviz,
+}
+
+// runRender prints a diagram of the definition
+func runRender(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("render", stderr)
+	formatName := flags.String("format", "plantuml", "diagram format: plantuml, mermaid, flowchart, table or dot")
+	definition, err := parseDefinitionArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	format, ok := renderFormats[strings.ToLower(*formatName)]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown format %q", *formatName))
+	}
+
+	stateMachine, err := buildStubMachine(definition, io.Discard)
+	if err != nil {
+		return err
+	}
+	defer fsm.RemoveStateMachine(definition.ID)
+
+	fmt.Fprint(stdout, stateMachine.GenerateDiagram(format))
+	return nil
+}
+
+// buildStubMachine builds a machine from a definition where every condition and guard expression passes
+// and every named action only reports that it ran
+func buildStubMachine(definition *fsm.Definition, actionLog io.Writer) (fsm.StateMachine[string, string, any], error) {
+	registry := fsm.NewRegistry[string, string, any]()
+	for _, name := range definition.ConditionNames() {
+		registry.RegisterConditionFunc(name, func(payload any) bool {
+			return true
+		})
+	}
+	for _, name := range definition.ActionNames() {
+		name := name
+		registry.RegisterActionFunc(name, func(from, to string, event string, payload any) error {
+			fmt.Fprintf(actionLog, "  perform %s: %s -> %s\n", name, from, to)
+			return nil
+		})
+	}
+
+	builder, err := fsm.ImportDefinition(definition, fsm.ImportOptions[string, string, any]{
+		Registry: registry,
+		ParseGuard: func(expression string) (fsm.Condition[any], error) {
+			return fsm.Named[any](expression, fsm.Always[any]()), nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(definition.ID)
+}
+
+// resolveState accepts a state name or value and returns the state value
+func resolveState(definition *fsm.Definition, text string) (string, bool) {
+	if value, ok := definition.StateValue(text); ok {
+		return value, true
+	}
+	for _, state := range definition.States {
+		if value, _ := definition.StateValue(state.Name); value == text {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// resolveEvent accepts an event name or value and returns the event value
+func resolveEvent(definition *fsm.Definition, text string) (string, bool) {
+	if value, ok := definition.EventValue(text); ok {
+		return value, true
+	}
+	for _, event := range definition.Events {
+		if value, _ := definition.EventValue(event.Name); value == text {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDefinition = `{
+	"id": "CliTestMachine",
+	"states": [{"name": "Draft", "value": "DRAFT"}, {"name": "Review", "value": "REVIEW"}, {"name": "Done", "value": "DONE"}],
+	"events": [{"name": "Submit", "value": "SUBMIT"}, {"name": "Approve", "value": "APPROVE"}, {"name": "Comment", "value": "COMMENT"}],
+	"transitions": [
+		{"from": ["Draft"], "to": ["Review"], "on": "Submit", "when": "isComplete", "perform": "notify"},
+		{"kind": "internal", "from": ["Review"], "on": "Comment"},
+		{"from": ["Review"], "to": ["Done"], "on": "Approve"},
+		{"from": ["Draft"], "to": ["Done"], "on": "Approve"}
+	]
+}`
+
+// writeDefinition stores a definition in a temporary file
+func writeDefinition(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "machine.fsm.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write definition: %v", err)
+	}
+	return path
+}
+
+// runCommand runs the CLI with the given arguments and input
+func runCommand(input string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestValidate tests that validation reports problems through the exit code
+func TestValidate(t *testing.T) {
+	code, stdout, _ := runCommand("", "validate", writeDefinition(t, testDefinition))
+	if code != exitOK || !strings.Contains(stdout, "ok (3 states, 3 events, 4 transitions)") {
+		t.Errorf("Expected valid definition, got code %d: %s", code, stdout)
+	}
+
+	broken := strings.Replace(testDefinition, `"to": ["Done"], "on": "Approve"}`, `"to": ["Gone"], "on": "Approve"}`, 1)
+	code, stdout, _ = runCommand("", "validate", writeDefinition(t, broken))
+	if code != exitError || !strings.Contains(stdout, `unknown state "Gone"`) {
+		t.Errorf("Expected validation failure, got code %d: %s", code, stdout)
+	}
+
+	if code, _, _ := runCommand("", "validate"); code != exitUsage {
+		t.Errorf("Expected usage error, got code %d", code)
+	}
+}
+
+// TestRender tests rendering in each supported format
+func TestRender(t *testing.T) {
+	path := writeDefinition(t, testDefinition)
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{"plantuml", "DRAFT --> REVIEW : SUBMIT"},
+		{"mermaid", "REVIEW --> REVIEW : COMMENT [internal]"},
+		{"table", "| `REVIEW` | `APPROVE` | `DONE` | External |"},
+		{"dot", `"REVIEW" -> "REVIEW" [label="COMMENT", style=dashed];`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			code, stdout, stderr := runCommand("", "render", "--format="+tc.format, path)
+			if code != exitOK {
+				t.Fatalf("Render failed with code %d: %s", code, stderr)
+			}
+			if !strings.Contains(stdout, tc.expected) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tc.expected, stdout)
+			}
+		})
+	}
+
+	if code, _, _ := runCommand("", "render", "--format=svg", path); code != exitUsage {
+		t.Errorf("Expected usage error for unknown format, got code %d", code)
+	}
+}
+
+// TestPaths tests path enumeration between states
+func TestPaths(t *testing.T) {
+	path := writeDefinition(t, testDefinition)
+
+	code, stdout, _ := runCommand("", "paths", "--from", "Draft", "--to", "DONE", path)
+	expected := "DRAFT --SUBMIT--> REVIEW --APPROVE--> DONE\nDRAFT --APPROVE--> DONE\n"
+	if code != exitOK || stdout != expected {
+		t.Errorf("Unexpected paths (code %d):\n%s", code, stdout)
+	}
+
+	if code, _, _ := runCommand("", "paths", "--from", "Done", "--to", "Draft", path); code != exitError {
+		t.Errorf("Expected failure when no path exists, got code %d", code)
+	}
+}
+
+// TestSimulate tests a scripted simulation session
+func TestSimulate(t *testing.T) {
+	path := writeDefinition(t, testDefinition)
+
+	code, stdout, stderr := runCommand("Approve\n:state Draft\nSUBMIT\nUnknown\n:quit\n", "simulate", "--from", "Review", path)
+	if code != exitOK {
+		t.Fatalf("Simulation failed with code %d: %s", code, stderr)
+	}
+
+	for _, expected := range []string{
+		"events: COMMENT, APPROVE",
+		"REVIEW --APPROVE--> DONE",
+		"events: none (final state)",
+		"perform notify: DRAFT -> REVIEW",
+		`unknown event "Unknown"`,
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected session output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// edge is a state-changing transition between two state values
+type edge struct {
+	event  string
+	target string
+}
+
+// runPaths prints every simple path between two states
+func runPaths(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("paths", stderr)
+	from := flags.String("from", "", "source state (required)")
+	to := flags.String("to", "", "target state (required)")
+	definition, err := parseDefinitionArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return usageError("both --from and --to are required")
+	}
+
+	source, ok := resolveState(definition, *from)
+	if !ok {
+		return fmt.Errorf("unknown state %q", *from)
+	}
+	target, ok := resolveState(definition, *to)
+	if !ok {
+		return fmt.Errorf("unknown state %q", *to)
+	}
+
+	paths := findPaths(buildGraph(definition), source, target)
+	if len(paths) == 0 {
+		return fmt.Errorf("no path from %s to %s", source, target)
+	}
+	for _, path := range paths {
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
+
+// buildGraph collects the state-changing edges of a definition keyed by source state value
+// Internal transitions are left out since they never lead anywhere else
+func buildGraph(definition *fsm.Definition) map[string][]edge {
+	graph := make(map[string][]edge)
+	for _, transition := range definition.Transitions {
+		if transition.Kind == fsm.KindInternal {
+			continue
+		}
+		event, _ := definition.EventValue(transition.On)
+		for _, fromName := range transition.From {
+			source, _ := definition.StateValue(fromName)
+			for _, toName := range transition.To {
+				target, _ := definition.StateValue(toName)
+				graph[source] = append(graph[source], edge{event: event, target: target})
+			}
+		}
+	}
+	return graph
+}
+
+// findPaths returns every path without repeated states from source to target,
+// formatted as "A --EVENT--> B", in depth-first order
+func findPaths(graph map[string][]edge, source, target string) []string {
+	var paths []string
+	visited := map[string]bool{source: true}
+
+	var walk func(state string, path string)
+	walk = func(state string, path string) {
+		for _, next := range graph[state] {
+			step := fmt.Sprintf("%s --%s--> %s", path, next.event, next.target)
+			if next.target == target {
+				paths = append(paths, step)
+				continue
+			}
+			if visited[next.target] {
+				continue
+			}
+			visited[next.target] = true
+			walk(next.target, step)
+			visited[next.target] = false
+		}
+	}
+
+	// A state trivially reaches itself; cycles back to it are listed after that
+	if source == target {
+		paths = append(paths, source)
+	}
+	walk(source, source)
+	return paths
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+const simulateHelp = `commands:
+  <event>         fire an event by name or value
+  :state <state>  jump to a state
+  :help           show this help
+  :quit           leave the simulator
+`
+
+// runSimulate starts an interactive session that fires events against a definition with stub actions
+func runSimulate(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := newFlagSet("simulate", stderr)
+	from := flags.String("from", "", "initial state, defaults to the first state of the definition")
+	definition, err := parseDefinitionArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	current, _ := definition.StateValue(definition.States[0].Name)
+	if *from != "" {
+		var ok bool
+		if current, ok = resolveState(definition, *from); !ok {
+			return fmt.Errorf("unknown state %q", *from)
+		}
+	}
+
+	stateMachine, err := buildStubMachine(definition, stdout)
+	if err != nil {
+		return err
+	}
+	defer fsm.RemoveStateMachine(definition.ID)
+
+	fmt.Fprintf(stdout, "simulating %s, type :help for commands\n", definition.ID)
+	scanner := bufio.NewScanner(stdin)
+	for {
+		printPosition(stdout, definition, current)
+		fmt.Fprint(stdout, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(stdout)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line == ":quit" || line == ":q":
+			return nil
+		case line == ":help":
+			fmt.Fprint(stdout, simulateHelp)
+			continue
+		case strings.HasPrefix(line, ":state"):
+			name := strings.TrimSpace(strings.TrimPrefix(line, ":state"))
+			if state, ok := resolveState(definition, name); ok {
+				current = state
+			} else {
+				fmt.Fprintf(stdout, "unknown state %q\n", name)
+			}
+			continue
+		}
+
+		event, ok := resolveEvent(definition, line)
+		if !ok {
+			fmt.Fprintf(stdout, "unknown event %q\n", line)
+			continue
+		}
+
+		next, err := stateMachine.FireEvent(current, event, nil)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s rejected in %s: %v\n", event, current, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s --%s--> %s\n", current, event, next)
+		current = next
+	}
+}
+
+// printPosition shows the current state and the events that have a transition from it
+func printPosition(w io.Writer, definition *fsm.Definition, current string) {
+	var events []string
+	seen := make(map[string]bool)
+	for _, transition := range definition.Transitions {
+		for _, fromName := range transition.From {
+			if value, _ := definition.StateValue(fromName); value != current {
+				continue
+			}
+			event, _ := definition.EventValue(transition.On)
+			if !seen[event] {
+				seen[event] = true
+				events = append(events, event)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "state: %s\n", current)
+	if len(events) == 0 {
+		fmt.Fprintln(w, "events: none (final state)")
+	} else {
+		fmt.Fprintf(w, "events: %s\n", strings.Join(events, ", "))
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// generatorInput is the data handed to the code template
+type generatorInput struct {
+	Source      string
+	Package     string
+	MachineID   string
+	MachineName string
+	StateType   string
+	EventType   string
+	PayloadType string
+	States      []constant
+	Events      []constant
+	Conditions  []string
+	Actions     []string
+	Transitions []transitionStatement
+	Guards      []guardVariable
+	NeedsNoop   bool
+}
+
+// constant is a generated typed constant
+type constant struct {
+	Name  string
+	Value string
+}
+
+// transitionStatement holds the resolved pieces of one builder chain
+type transitionStatement struct {
+	Kind    string
+	From    []string
+	To      []string
+	On      string
+	When    string
+	Guard   string
+	Perform string
+}
+
+// guardVariable is a guard expression compiled once when the machine is built
+type guardVariable struct {
+	Name       string
+	Expression string
+}
+
+// Generate renders Go source for a definition
+// Parameters:
+//
+//	definition: A validated definition
+//	packageName: Package of the generated file, overrides the definition's package when not empty
+//	source: Name of the definition file, recorded in the generated header
+//
+// Returns:
+//
+//	Formatted Go source and possible error
+func Generate(definition *fsm.Definition, packageName, source string) ([]byte, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	input := generatorInput{
+		Source:      source,
+		Package:     packageName,
+		MachineID:   definition.ID,
+		MachineName: exportedName(definition.ID),
+		StateType:   definition.StateType,
+		EventType:   definition.EventType,
+		PayloadType: definition.PayloadType,
+		Conditions:  exportedNames(definition.ConditionNames()),
+		Actions:     exportedNames(definition.ActionNames()),
+	}
+	if input.Package == "" {
+		input.Package = definition.Package
+	}
+	if input.Package == "" {
+		return nil, fmt.Errorf("no package name given in the definition or on the command line")
+	}
+	if input.StateType == "" || input.EventType == "" {
+		return nil, fmt.Errorf("definition must name its stateType and eventType for code generation")
+	}
+	if input.MachineName == "" {
+		return nil, fmt.Errorf("machine id %q does not yield a Go identifier", definition.ID)
+	}
+	if input.PayloadType == "" {
+		input.PayloadType = "any"
+	}
+	if err := checkMethodNames("condition", definition.ConditionNames()); err != nil {
+		return nil, err
+	}
+	if err := checkMethodNames("action", definition.ActionNames()); err != nil {
+		return nil, err
+	}
+
+	for _, state := range definition.States {
+		value, _ := definition.StateValue(state.Name)
+		input.States = append(input.States, constant{Name: state.Name, Value: value})
+	}
+	for _, event := range definition.Events {
+		value, _ := definition.EventValue(event.Name)
+		input.Events = append(input.Events, constant{Name: event.Name, Value: value})
+	}
+	for _, transition := range definition.Transitions {
+		kind := transition.Kind
+		if kind == "" {
+			kind = fsm.KindExternal
+		}
+		var guard string
+		if transition.Guard != "" {
+			guard = fmt.Sprintf("guard%d", len(input.Guards)+1)
+			input.Guards = append(input.Guards, guardVariable{Name: guard, Expression: transition.Guard})
+		}
+		input.NeedsNoop = input.NeedsNoop || transition.Perform == ""
+		input.Transitions = append(input.Transitions, transitionStatement{
+			Kind:    kind,
+			From:    transition.From,
+			To:      transition.To,
+			On:      transition.On,
+			When:    exportedName(transition.When),
+			Guard:   guard,
+			Perform: exportedName(transition.Perform),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, input); err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// exportedName turns a name such as "order-machine" or "hasAmount" into an exported Go identifier
+func exportedName(name string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// checkMethodNames verifies that names map to distinct, non-empty Go method names
+func checkMethodNames(kind string, names []string) error {
+	seen := make(map[string]string)
+	for _, name := range names {
+		method := exportedName(name)
+		if method == "" {
+			return fmt.Errorf("%s name %q does not yield a Go identifier", kind, name)
+		}
+		if other, ok := seen[method]; ok {
+			return fmt.Errorf("%s names %q and %q both map to method %s", kind, other, name, method)
+		}
+		seen[method] = name
+	}
+	return nil
+}
+
+// exportedNames applies exportedName to each name
+func exportedNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, exportedName(name))
+	}
+	return result
+}
+
+var codeTemplate = template.Must(template.New("fsm").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`// Code generated by fsmgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// {{.StateType}} enumerates the states of the {{.MachineID}} state machine
+type {{.StateType}} string
+
+const (
+{{- range .States}}
+	{{.Name}} {{$.StateType}} = {{printf "%q" .Value}}
+{{- end}}
+)
+
+// All{{.StateType}}s returns every {{.StateType}} in declaration order
+func All{{.StateType}}s() []{{.StateType}} {
+	return []{{.StateType}}{ {{- range $i, $s := .States}}{{if $i}}, {{end}}{{$s.Name}}{{end -}} }
+}
+
+// String implements fmt.Stringer
+func (s {{.StateType}}) String() string {
+	return string(s)
+}
+
+// IsValid reports whether s is a declared {{.StateType}}
+func (s {{.StateType}}) IsValid() bool {
+	switch s {
+	case {{range $i, $s := .States}}{{if $i}}, {{end}}{{$s.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s {{.StateType}}) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("unknown {{.StateType}} %q", string(s))
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *{{.StateType}}) UnmarshalText(text []byte) error {
+	value := {{.StateType}}(text)
+	if !value.IsValid() {
+		return fmt.Errorf("unknown {{.StateType}} %q", string(text))
+	}
+	*s = value
+	return nil
+}
+
+// {{.EventType}} enumerates the events of the {{.MachineID}} state machine
+type {{.EventType}} string
+
+const (
+{{- range .Events}}
+	{{.Name}} {{$.EventType}} = {{printf "%q" .Value}}
+{{- end}}
+)
+
+// All{{.EventType}}s returns every {{.EventType}} in declaration order
+func All{{.EventType}}s() []{{.EventType}} {
+	return []{{.EventType}}{ {{- range $i, $e := .Events}}{{if $i}}, {{end}}{{$e.Name}}{{end -}} }
+}
+
+// String implements fmt.Stringer
+func (e {{.EventType}}) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is a declared {{.EventType}}
+func (e {{.EventType}}) IsValid() bool {
+	switch e {
+	case {{range $i, $e := .Events}}{{if $i}}, {{end}}{{$e.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (e {{.EventType}}) MarshalText() ([]byte, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("unknown {{.EventType}} %q", string(e))
+	}
+	return []byte(e), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (e *{{.EventType}}) UnmarshalText(text []byte) error {
+	value := {{.EventType}}(text)
+	if !value.IsValid() {
+		return fmt.Errorf("unknown {{.EventType}} %q", string(text))
+	}
+	*e = value
+	return nil
+}
+{{if .Conditions}}
+// {{.MachineName}}Conditions declares the named conditions used by the {{.MachineID}} state machine
+type {{.MachineName}}Conditions interface {
+{{- range .Conditions}}
+	{{.}}(payload {{$.PayloadType}}) bool
+{{- end}}
+}
+{{end}}{{if .Actions}}
+// {{.MachineName}}Actions declares the named actions used by the {{.MachineID}} state machine
+type {{.MachineName}}Actions interface {
+{{- range .Actions}}
+	{{.}}(from, to {{$.StateType}}, event {{$.EventType}}, payload {{$.PayloadType}}) error
+{{- end}}
+}
+{{end}}
+// Build{{.MachineName}} builds and registers the {{.MachineID}} state machine
+func Build{{.MachineName}}({{if .Conditions}}conditions {{.MachineName}}Conditions{{end}}{{if and .Conditions .Actions}}, {{end}}{{if .Actions}}actions {{.MachineName}}Actions{{end}}) (fsm.StateMachine[{{.StateType}}, {{.EventType}}, {{.PayloadType}}], error) {
+	builder := fsm.NewStateMachineBuilder[{{.StateType}}, {{.EventType}}, {{.PayloadType}}]()
+{{- if .NeedsNoop}}
+	noop := func(from, to {{.StateType}}, event {{.EventType}}, payload {{.PayloadType}}) error {
+		return nil
+	}
+{{- end}}
+{{- range .Guards}}
+	{{.Name}}, err := fsm.CompileExpression[{{$.PayloadType}}]({{printf "%q" .Expression}})
+	if err != nil {
+		return nil, err
+	}
+{{- end}}
+{{range .Transitions}}
+	builder.
+{{- if eq .Kind "internal"}}InternalTransition().
+		Within({{index .From 0}}).
+{{- else if eq .Kind "parallel"}}ExternalParallelTransition().
+		From({{index .From 0}}).
+		ToAmong({{join .To ", "}}).
+{{- else if gt (len .From) 1}}ExternalTransitions().
+		FromAmong({{join .From ", "}}).
+		To({{index .To 0}}).
+{{- else}}ExternalTransition().
+		From({{index .From 0}}).
+		To({{index .To 0}}).
+{{- end}}
+		On({{.On}}).
+{{- if .When}}
+		WhenFunc(conditions.{{.When}}).
+{{- end}}
+{{- if .Guard}}
+		When({{.Guard}}).
+{{- end}}
+		PerformFunc({{if .Perform}}actions.{{.Perform}}{{else}}noop{{end}})
+{{end}}
+	return builder.Build({{printf "%q" .MachineID}})
+}
+`))
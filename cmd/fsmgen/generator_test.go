@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// TestGenerateExampleUpToDate tests that the checked-in example matches the generator output
+func TestGenerateExampleUpToDate(t *testing.T) {
+	definition, err := fsm.LoadDefinition("../../examples/codegen/order.fsm.json")
+	if err != nil {
+		t.Fatalf("Failed to load definition: %v", err)
+	}
+
+	code, err := Generate(definition, "", "order.fsm.json")
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+
+	existing, err := os.ReadFile("../../examples/codegen/order_fsm.go")
+	if err != nil {
+		t.Fatalf("Failed to read generated example: %v", err)
+	}
+	if !bytes.Equal(code, existing) {
+		t.Error("examples/codegen/order_fsm.go is stale, run go generate ./examples/codegen")
+	}
+}
+
+// TestGenerateKinds tests that every transition kind is rendered with the matching builder chain
+func TestGenerateKinds(t *testing.T) {
+	definition, err := fsm.ParseDefinition([]byte(`{
+		"id": "doc-flow",
+		"stateType": "DocState",
+		"eventType": "DocEvent",
+		"states": [{"name": "Draft"}, {"name": "Review"}, {"name": "Notify"}],
+		"events": [{"name": "Edit"}, {"name": "Submit"}],
+		"transitions": [
+			{"kind": "internal", "from": ["Draft"], "on": "Edit"},
+			{"kind": "parallel", "from": ["Draft"], "to": ["Review", "Notify"], "on": "Submit", "when": "is-complete"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse definition: %v", err)
+	}
+
+	code, err := Generate(definition, "docs", "doc.fsm.json")
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+
+	for _, expected := range []string{
+		"package docs",
+		"func BuildDocFlow(conditions DocFlowConditions)",
+		"IsComplete(payload any) bool",
+		"Within(Draft).\n\t\tOn(Edit).\n\t\tPerformFunc(noop)",
+		"ToAmong(Review, Notify)",
+		`Review DocState = "Review"`,
+	} {
+		if !strings.Contains(string(code), expected) {
+			t.Errorf("Expected generated code to contain %q", expected)
+		}
+	}
+	if strings.Contains(string(code), "always") {
+		t.Error("Expected unguarded transitions to be generated without a condition")
+	}
+}
+
+// TestGenerateGuards tests that guard expressions are compiled once and combined with named conditions
+func TestGenerateGuards(t *testing.T) {
+	definition, err := fsm.ParseDefinition([]byte(`{
+		"id": "refunds",
+		"stateType": "RefundState",
+		"eventType": "RefundEvent",
+		"payloadType": "*Refund",
+		"states": [{"name": "Requested"}, {"name": "Approved"}],
+		"events": [{"name": "Approve"}],
+		"transitions": [
+			{"from": ["Requested"], "to": ["Approved"], "on": "Approve", "guard": "payload.Amount < 100"},
+			{"from": ["Requested"], "to": ["Approved"], "on": "Approve", "when": "reviewed", "guard": "payload.Reason != ''"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse definition: %v", err)
+	}
+
+	code, err := Generate(definition, "refunds", "refunds.fsm.json")
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+
+	for _, expected := range []string{
+		`guard1, err := fsm.CompileExpression[*Refund]("payload.Amount < 100")`,
+		`guard2, err := fsm.CompileExpression[*Refund]("payload.Reason != ''")`,
+		"When(guard1)",
+		"WhenFunc(conditions.Reviewed).\n\t\tWhen(guard2)",
+	} {
+		if !strings.Contains(string(code), expected) {
+			t.Errorf("Expected generated code to contain %q", expected)
+		}
+	}
+	if strings.Contains(string(code), "always") {
+		t.Error("Expected no always condition when every transition is guarded")
+	}
+}
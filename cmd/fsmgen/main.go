@@ -0,0 +1,65 @@
+// Command fsmgen generates typed state and event constants and builder code from a state machine definition
+//
+// Usage:
+//
+//	fsmgen -in order.fsm.json [-out order_fsm.go] [-package order]
+//
+// It is intended to be invoked through go generate:
+//
+//	//go:generate go run github.com/lingcoder/fsm-go/cmd/fsmgen -in order.fsm.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+func main() {
+	in := flag.String("in", "", "definition file to read (required)")
+	out := flag.String("out", "", "Go file to write, defaults to the definition name with a _fsm.go suffix")
+	pkg := flag.String("package", "", "package of the generated file, defaults to the definition's package or $GOPACKAGE")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out, *pkg); err != nil {
+		fmt.Fprintf(os.Stderr, "fsmgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the output file for one definition
+func run(in, out, pkg string) error {
+	definition, err := fsm.LoadDefinition(in)
+	if err != nil {
+		return err
+	}
+
+	if pkg == "" && definition.Package == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	if out == "" {
+		out = defaultOutput(in)
+	}
+
+	code, err := Generate(definition, pkg, filepath.Base(in))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, code, 0o644)
+}
+
+// defaultOutput derives "order_fsm.go" from "order.fsm.json" or "order.json"
+func defaultOutput(in string) string {
+	base := strings.TrimSuffix(in, filepath.Ext(in))
+	base = strings.TrimSuffix(base, ".fsm")
+	return base + "_fsm.go"
+}
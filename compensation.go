@@ -0,0 +1,133 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PhaseCompensation marks a panic recovered while running a compensation action
+const PhaseCompensation = "compensation"
+
+// SpanCompensation is the name of the span opened for each compensation action
+const SpanCompensation = "fsm.compensation"
+
+// CompensationError is returned when a firing failed after some transitions had completed
+// and their compensation actions were run to undo them
+type CompensationError[S comparable] struct {
+	// Err is the failure that triggered the compensation
+	Err error
+	// Compensated are the targets of the transitions whose compensation ran, in the order it ran
+	Compensated []S
+	// Errors holds the failures of compensation actions, keyed by target state
+	Errors map[S]error
+}
+
+// Error implements error interface
+func (e *CompensationError[S]) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("%v (compensated %v)", e.Err, e.Compensated)
+	}
+
+	messages := make([]string, 0, len(e.Errors))
+	for _, target := range e.Compensated {
+		if err, ok := e.Errors[target]; ok {
+			messages = append(messages, fmt.Sprintf("%v: %v", target, err))
+		}
+	}
+	return fmt.Sprintf("%v (compensation failed: %s)", e.Err, strings.Join(messages, "; "))
+}
+
+// Is reports whether the original failure or any compensation failure matches target
+func (e *CompensationError[S]) Is(target error) bool {
+	for _, err := range e.Unwrap() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error, the original failure first, that matches target
+func (e *CompensationError[S]) As(target interface{}) bool {
+	for _, err := range e.Unwrap() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the original failure followed by the compensation failures in the order they occurred
+func (e *CompensationError[S]) Unwrap() []error {
+	errs := []error{e.Err}
+	for _, target := range e.Compensated {
+		if err, ok := e.Errors[target]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// compensate runs the compensation actions of completed transitions in reverse order
+// It returns the targets of the completed transitions without a compensation, which remain in effect,
+// and cause unchanged when none of the transitions declares a compensation
+func (sm *StateMachineImpl[S, E, P]) compensate(f *firing[S, E], completed []*Transition[S, E, P], payload P, cause error) ([]S, error) {
+	var remaining []S
+	for _, transition := range completed {
+		if transition.Compensation == nil {
+			remaining = append(remaining, transition.Target.GetID())
+		}
+	}
+
+	// A failed transition may already have compensated its own partial run, the errors are then reported together
+	compensationErr, ok := cause.(*CompensationError[S])
+	if !ok {
+		compensationErr = &CompensationError[S]{Err: cause}
+	}
+	for i := len(completed) - 1; i >= 0; i-- {
+		transition := completed[i]
+		if transition.Compensation == nil {
+			continue
+		}
+
+		target := transition.Target.GetID()
+		compensationErr.Compensated = append(compensationErr.Compensated, target)
+		if err := sm.runCompensation(f, transition, payload); err != nil {
+			if compensationErr.Errors == nil {
+				compensationErr.Errors = make(map[S]error)
+			}
+			compensationErr.Errors[target] = err
+		}
+	}
+
+	if len(compensationErr.Compensated) == 0 {
+		return remaining, cause
+	}
+	return remaining, compensationErr
+}
+
+// runCompensation executes the compensation action of a single transition
+func (sm *StateMachineImpl[S, E, P]) runCompensation(f *firing[S, E], transition *Transition[S, E, P], payload P) error {
+	_, span := sm.startSpan(f.ctx, SpanCompensation, transition.Source.GetID(), transition.Event,
+		Attr(AttributeTarget, fmt.Sprint(transition.Target.GetID())))
+
+	var err error
+	if panicErr := sm.protect(PhaseCompensation, transition, func() {
+		if contextual, ok := transition.Compensation.(ContextualAction[S, E, P]); ok {
+			err = contextual.ExecuteContext(sm.transitionContext(f, transition), payload)
+		} else {
+			err = transition.Compensation.Execute(transition.Source.GetID(), transition.Target.GetID(), transition.Event, payload)
+		}
+	}); panicErr != nil {
+		err = panicErr
+	}
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// buildCompensationMachine creates a machine whose Event1 reserves (B), notifies (C) and charges (D) in parallel
+// Reserve and notify declare compensations, charging fails
+func buildCompensationMachine(t *testing.T, machineId string, log *[]string, releaseErr error, concurrent bool) StateMachine[testState, testEvent, testPayload] {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	if concurrent {
+		builder.WithParallelExecution(ParallelOptions{MaxConcurrency: 1})
+	}
+
+	step := func(name string, err error) func(from, to testState, event testEvent, payload testPayload) error {
+		return func(from, to testState, event testEvent, payload testPayload) error {
+			*log = append(*log, name)
+			return err
+		}
+	}
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(step("reserve", nil)).
+		CompensateFunc(step("release", releaseErr))
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateC).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(step("notify", nil)).
+		CompensateFunc(step("retract", nil))
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateD).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(step("charge", errors.New("card declined")))
+
+	sm, err := builder.Build(machineId)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	return sm
+}
+
+// TestCompensation tests that completed branches are compensated in reverse order when a later branch fails
+func TestCompensation(t *testing.T) {
+	for _, concurrent := range []bool{false, true} {
+		var log []string
+		machineId := "CompensationTest"
+		if concurrent {
+			machineId = "CompensationConcurrentTest"
+		}
+		sm := buildCompensationMachine(t, machineId, &log, nil, concurrent)
+
+		targets, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+		var compensationErr *CompensationError[testState]
+		if !errors.As(err, &compensationErr) {
+			t.Fatalf("Expected *CompensationError, got %v", err)
+		}
+		if !errors.Is(err, ErrActionExecutionFailed) {
+			t.Error("Expected the original failure to be preserved")
+		}
+		if len(targets) != 0 {
+			t.Errorf("Expected no targets after compensation, got %v", targets)
+		}
+
+		// Concurrent branches run in any order, the compensations always come last in reverse declaration order
+		expected := []string{"reserve", "notify", "charge", "retract", "release"}
+		if len(log) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, log)
+		}
+		first := 0
+		if concurrent {
+			first = 3
+		}
+		for i := first; i < len(expected); i++ {
+			if log[i] != expected[i] {
+				t.Errorf("Expected %v, got %v", expected, log)
+				break
+			}
+		}
+		if len(compensationErr.Compensated) != 2 || compensationErr.Compensated[0] != StateC || compensationErr.Compensated[1] != StateB {
+			t.Errorf("Expected compensated [C B], got %v", compensationErr.Compensated)
+		}
+	}
+}
+
+// TestCompensationFailure tests that compensation failures are returned next to the original failure
+func TestCompensationFailure(t *testing.T) {
+	var log []string
+	releaseErr := errors.New("warehouse offline")
+	sm := buildCompensationMachine(t, "CompensationFailureTest", &log, releaseErr, false)
+
+	_, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+	var compensationErr *CompensationError[testState]
+	if !errors.As(err, &compensationErr) {
+		t.Fatalf("Expected *CompensationError, got %v", err)
+	}
+	if !errors.Is(err, releaseErr) || !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected both the original and the compensation failure, got %v", err)
+	}
+	if compensationErr.Errors[StateB] != releaseErr || len(compensationErr.Errors) != 1 {
+		t.Errorf("Expected release failure keyed by B, got %v", compensationErr.Errors)
+	}
+	if err.Error() != "action execution failed in CompensationFailureTest: A --Event1--> D: card declined (compensation failed: B: warehouse offline)" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
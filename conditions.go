@@ -0,0 +1,377 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compositeCondition is implemented by conditions built from other conditions
+// The state machine evaluates the operands itself so that contextual operands receive the transition context
+type compositeCondition[P any] interface {
+	// satisfiedBy combines the results of the operands, evaluating them with eval and short-circuiting
+	satisfiedBy(eval func(operand Condition[P]) bool) bool
+
+	// explain is like Explain, evaluating with eval the operands that are neither combined nor explaining
+	explain(payload P, eval func(operand Condition[P]) bool) (bool, string)
+}
+
+// evaluateCondition evaluates a condition, descending into composite conditions and evaluating
+// the other conditions with leaf
+func evaluateCondition[P any](condition Condition[P], leaf func(condition Condition[P]) bool) bool {
+	if composite, ok := condition.(compositeCondition[P]); ok {
+		return composite.satisfiedBy(func(operand Condition[P]) bool {
+			return evaluateCondition(operand, leaf)
+		})
+	}
+	return leaf(condition)
+}
+
+// explainWith explains a condition, evaluating with eval the conditions that are neither combined nor explaining
+// The reason is empty when the condition gives none
+func explainWith[P any](condition Condition[P], payload P, eval func(condition Condition[P]) bool) (bool, string) {
+	switch c := condition.(type) {
+	case compositeCondition[P]:
+		return c.explain(payload, eval)
+	case ExplainingCondition[P]:
+		return c.Explain(payload)
+	default:
+		return eval(condition), ""
+	}
+}
+
+// evaluatePlainly returns an evaluator calling IsSatisfied, for explanations given outside of a state machine
+func evaluatePlainly[P any](payload P) func(condition Condition[P]) bool {
+	return func(condition Condition[P]) bool {
+		return condition.IsSatisfied(payload)
+	}
+}
+
+// DescribeCondition returns the name of a condition as shown in diagrams and explanations
+// Conditions created with Named, And, Or, Not, Always and Never describe themselves, as does any condition
+// implementing fmt.Stringer; other conditions have no description
+// Parameters:
+//
+//	condition: The condition to describe
+//
+// Returns:
+//
+//	The description, or an empty string if the condition has none
+func DescribeCondition[P any](condition Condition[P]) string {
+	if stringer, ok := condition.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return ""
+}
+
+// constantCondition is the condition returned by Always and Never
+type constantCondition[P any] bool
+
+// Always returns a condition that is always satisfied
+func Always[P any]() Condition[P] {
+	return constantCondition[P](true)
+}
+
+// Never returns a condition that is never satisfied
+func Never[P any]() Condition[P] {
+	return constantCondition[P](false)
+}
+
+// IsSatisfied implements Condition interface
+func (c constantCondition[P]) IsSatisfied(payload P) bool {
+	return bool(c)
+}
+
+// Explain implements ExplainingCondition interface
+func (c constantCondition[P]) Explain(payload P) (bool, string) {
+	return bool(c), c.String()
+}
+
+// String returns "always" or "never"
+func (c constantCondition[P]) String() string {
+	if c {
+		return "always"
+	}
+	return "never"
+}
+
+// namedCondition is the condition returned by Named
+type namedCondition[P any] struct {
+	name      string
+	condition Condition[P]
+}
+
+// Named gives a condition a name shown in diagrams, ShowStateMachine and explanations
+// Parameters:
+//
+//	name: The name of the condition
+//	condition: The condition to name
+//
+// Returns:
+//
+//	A condition behaving like condition
+func Named[P any](name string, condition Condition[P]) Condition[P] {
+	return namedCondition[P]{name: name, condition: condition}
+}
+
+// IsSatisfied implements Condition interface
+func (c namedCondition[P]) IsSatisfied(payload P) bool {
+	return c.condition.IsSatisfied(payload)
+}
+
+// Explain implements ExplainingCondition interface, prefixing the reason of the condition with its name
+func (c namedCondition[P]) Explain(payload P) (bool, string) {
+	return c.explain(payload, evaluatePlainly(payload))
+}
+
+// explain implements compositeCondition interface
+func (c namedCondition[P]) explain(payload P, eval func(operand Condition[P]) bool) (bool, string) {
+	satisfied, reason := explainWith(c.condition, payload, eval)
+	if reason != "" {
+		return satisfied, c.name + ": " + reason
+	}
+	return satisfied, describeResult(c.name, satisfied)
+}
+
+// String returns the name of the condition
+func (c namedCondition[P]) String() string {
+	return c.name
+}
+
+// satisfiedBy implements compositeCondition interface
+func (c namedCondition[P]) satisfiedBy(eval func(operand Condition[P]) bool) bool {
+	return eval(c.condition)
+}
+
+// allCondition is the condition returned by And
+type allCondition[P any] []Condition[P]
+
+// And returns a condition satisfied when all conditions are, evaluated in order until one is not satisfied
+// It implements ExplainingCondition, its explanation names the first condition that is not satisfied
+// Parameters:
+//
+//	conditions: The conditions to combine, And() is always satisfied
+//
+// Returns:
+//
+//	The combined condition
+func And[P any](conditions ...Condition[P]) Condition[P] {
+	return allCondition[P](conditions)
+}
+
+// IsSatisfied implements Condition interface
+func (c allCondition[P]) IsSatisfied(payload P) bool {
+	return c.satisfiedBy(func(operand Condition[P]) bool {
+		return operand.IsSatisfied(payload)
+	})
+}
+
+// satisfiedBy implements compositeCondition interface
+func (c allCondition[P]) satisfiedBy(eval func(operand Condition[P]) bool) bool {
+	for _, condition := range c {
+		if !eval(condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// Explain implements ExplainingCondition interface
+func (c allCondition[P]) Explain(payload P) (bool, string) {
+	return c.explain(payload, evaluatePlainly(payload))
+}
+
+// explain implements compositeCondition interface
+func (c allCondition[P]) explain(payload P, eval func(operand Condition[P]) bool) (bool, string) {
+	for i, condition := range c {
+		if satisfied, reason := explainOperand(condition, i, len(c), payload, eval); !satisfied {
+			return false, reason
+		}
+	}
+	return true, describeResult(c.String(), true)
+}
+
+// String joins the descriptions of the conditions with &&
+func (c allCondition[P]) String() string {
+	if len(c) == 0 {
+		return "always"
+	}
+	return joinOperands(c, " && ")
+}
+
+// anyCondition is the condition returned by Or
+type anyCondition[P any] []Condition[P]
+
+// Or returns a condition satisfied when any condition is, evaluated in order until one is satisfied
+// It implements ExplainingCondition, its explanation names the first condition that is satisfied, or the reasons of all of them
+// Parameters:
+//
+//	conditions: The conditions to combine, Or() is never satisfied
+//
+// Returns:
+//
+//	The combined condition
+func Or[P any](conditions ...Condition[P]) Condition[P] {
+	return anyCondition[P](conditions)
+}
+
+// IsSatisfied implements Condition interface
+func (c anyCondition[P]) IsSatisfied(payload P) bool {
+	return c.satisfiedBy(func(operand Condition[P]) bool {
+		return operand.IsSatisfied(payload)
+	})
+}
+
+// satisfiedBy implements compositeCondition interface
+func (c anyCondition[P]) satisfiedBy(eval func(operand Condition[P]) bool) bool {
+	for _, condition := range c {
+		if eval(condition) {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain implements ExplainingCondition interface
+func (c anyCondition[P]) Explain(payload P) (bool, string) {
+	return c.explain(payload, evaluatePlainly(payload))
+}
+
+// explain implements compositeCondition interface
+func (c anyCondition[P]) explain(payload P, eval func(operand Condition[P]) bool) (bool, string) {
+	reasons := make([]string, 0, len(c))
+	for i, condition := range c {
+		satisfied, reason := explainOperand(condition, i, len(c), payload, eval)
+		if satisfied {
+			return true, reason
+		}
+		reasons = append(reasons, reason)
+	}
+	if len(reasons) == 0 {
+		return false, describeResult(c.String(), false)
+	}
+	return false, strings.Join(reasons, ", ")
+}
+
+// String joins the descriptions of the conditions with ||
+func (c anyCondition[P]) String() string {
+	if len(c) == 0 {
+		return "never"
+	}
+	return joinOperands(c, " || ")
+}
+
+// notCondition is the condition returned by Not
+type notCondition[P any] struct {
+	condition Condition[P]
+}
+
+// Not returns a condition satisfied when condition is not
+// Parameters:
+//
+//	condition: The condition to negate
+//
+// Returns:
+//
+//	The negated condition
+func Not[P any](condition Condition[P]) Condition[P] {
+	return notCondition[P]{condition: condition}
+}
+
+// IsSatisfied implements Condition interface
+func (c notCondition[P]) IsSatisfied(payload P) bool {
+	return !c.condition.IsSatisfied(payload)
+}
+
+// satisfiedBy implements compositeCondition interface
+func (c notCondition[P]) satisfiedBy(eval func(operand Condition[P]) bool) bool {
+	return !eval(c.condition)
+}
+
+// Explain implements ExplainingCondition interface
+func (c notCondition[P]) Explain(payload P) (bool, string) {
+	return c.explain(payload, evaluatePlainly(payload))
+}
+
+// explain implements compositeCondition interface
+func (c notCondition[P]) explain(payload P, eval func(operand Condition[P]) bool) (bool, string) {
+	satisfied, reason := explainOperand(c.condition, 0, 1, payload, eval)
+	return !satisfied, "not (" + reason + ")"
+}
+
+// String prefixes the description of the condition with !
+func (c notCondition[P]) String() string {
+	return "!" + describeOperand(c.condition, 0, 1)
+}
+
+// explainOperand evaluates the operand at index of a combined condition with explainWith and describes the result
+func explainOperand[P any](condition Condition[P], index, count int, payload P, eval func(condition Condition[P]) bool) (bool, string) {
+	satisfied, reason := explainWith(condition, payload, eval)
+	if reason == "" {
+		reason = describeResult(describeOperand(condition, index, count), satisfied)
+	}
+	return satisfied, reason
+}
+
+// describeOperand describes the operand at index of a combined condition, by position when it has no name
+// Combined operands and names containing spaces, such as expressions, are parenthesized
+func describeOperand[P any](condition Condition[P], index, count int) string {
+	switch condition.(type) {
+	case allCondition[P], anyCondition[P]:
+		return "(" + DescribeCondition(condition) + ")"
+	}
+	if name := DescribeCondition(condition); strings.ContainsAny(name, " \t") {
+		return "(" + name + ")"
+	} else if name != "" {
+		return name
+	}
+	if count == 1 {
+		return "condition"
+	}
+	return fmt.Sprintf("condition %d", index+1)
+}
+
+// isLabelled reports whether a condition and all of its operands have a name, so its description holds no placeholder
+// Always and Never are not labelled, they are not names a registry or an expression could resolve
+func isLabelled[P any](condition Condition[P]) bool {
+	switch c := condition.(type) {
+	case constantCondition[P]:
+		return false
+	case namedCondition[P]:
+		return c.name != ""
+	case allCondition[P]:
+		return allLabelled(c)
+	case anyCondition[P]:
+		return allLabelled(c)
+	case notCondition[P]:
+		return isLabelled(c.condition)
+	default:
+		return DescribeCondition(condition) != ""
+	}
+}
+
+// allLabelled reports whether a combined condition has operands and all of them are labelled
+func allLabelled[P any](conditions []Condition[P]) bool {
+	for _, condition := range conditions {
+		if !isLabelled(condition) {
+			return false
+		}
+	}
+	return len(conditions) > 0
+}
+
+// joinOperands joins the descriptions of the operands of a combined condition
+func joinOperands[P any](conditions []Condition[P], separator string) string {
+	names := make([]string, len(conditions))
+	for i, condition := range conditions {
+		names[i] = describeOperand(condition, i, len(conditions))
+	}
+	return strings.Join(names, separator)
+}
+
+// describeResult describes whether a named condition is satisfied
+func describeResult(name string, satisfied bool) string {
+	if satisfied {
+		return name + " satisfied"
+	}
+	return name + " not satisfied"
+}
@@ -0,0 +1,120 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestConditionCombinators tests the results and short-circuiting of combined conditions
+func TestConditionCombinators(t *testing.T) {
+	var evaluated []string
+	condition := func(name string, result bool) Condition[testPayload] {
+		return Named[testPayload](name, ConditionFunc[testPayload](func(payload testPayload) bool {
+			evaluated = append(evaluated, name)
+			return result
+		}))
+	}
+
+	testCases := []struct {
+		condition Condition[testPayload]
+		expected  bool
+		evaluated string
+	}{
+		{And(condition("a", true), condition("b", false), condition("c", true)), false, "a b"},
+		{Or(condition("a", false), condition("b", true), condition("c", true)), true, "a b"},
+		{Not(condition("a", true)), false, "a"},
+		{And(Always[testPayload](), Not(Never[testPayload]())), true, ""},
+		{And[testPayload](), true, ""},
+		{Or[testPayload](), false, ""},
+	}
+	for i, tc := range testCases {
+		evaluated = nil
+		if satisfied := tc.condition.IsSatisfied(testPayload{}); satisfied != tc.expected {
+			t.Errorf("Case %d: expected %v, got %v", i, tc.expected, satisfied)
+		}
+		if got := strings.Join(evaluated, " "); got != tc.evaluated {
+			t.Errorf("Case %d: expected %q to be evaluated, got %q", i, tc.evaluated, got)
+		}
+	}
+}
+
+// TestConditionExplanations tests the descriptions and reasons of combined conditions
+func TestConditionExplanations(t *testing.T) {
+	hasAmount := Named[testPayload]("hasAmount", ConditionFunc[testPayload](func(payload testPayload) bool {
+		return payload.Value != ""
+	}))
+	isVIP := Named[testPayload]("isVIP", ConditionFunc[testPayload](func(payload testPayload) bool {
+		return payload.Value == "vip"
+	}))
+	isBlocked := Named[testPayload]("isBlocked", ConditionFunc[testPayload](func(payload testPayload) bool {
+		return payload.Value == "blocked"
+	}))
+	condition := And(hasAmount, Or(isVIP, Not(isBlocked)))
+
+	if description := DescribeCondition[testPayload](condition); description != "hasAmount && (isVIP || !isBlocked)" {
+		t.Errorf("Unexpected description %q", description)
+	}
+	if description := DescribeCondition(And[testPayload](hasAmount, ConditionFunc[testPayload](nil))); description != "hasAmount && condition 2" {
+		t.Errorf("Unexpected description of an unnamed operand %q", description)
+	}
+
+	testCases := []struct {
+		value     string
+		satisfied bool
+		reason    string
+	}{
+		{"", false, "hasAmount not satisfied"},
+		{"blocked", false, "isVIP not satisfied, not (isBlocked satisfied)"},
+		{"vip", true, "hasAmount && (isVIP || !isBlocked) satisfied"},
+	}
+	for _, tc := range testCases {
+		satisfied, reason := condition.(ExplainingCondition[testPayload]).Explain(testPayload{Value: tc.value})
+		if satisfied != tc.satisfied || reason != tc.reason {
+			t.Errorf("Payload %q: expected %v %q, got %v %q", tc.value, tc.satisfied, tc.reason, satisfied, reason)
+		}
+	}
+}
+
+// TestNamedConditionsInMachine tests that condition names show up in descriptors, diagrams and explanations
+// and that contextual operands receive the transition context
+func TestNamedConditionsInMachine(t *testing.T) {
+	isReviewer := ContextualConditionFunc[testState, testEvent, testPayload](
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) bool {
+			return tc.MachineId == "NamedConditionsTest"
+		})
+	isSubmitted := ConditionFunc[testPayload](func(payload testPayload) bool {
+		return payload.Value == "submitted"
+	})
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(And(Named[testPayload]("submitted", isSubmitted), Named[testPayload]("reviewer", isReviewer))).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("NamedConditionsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if target, err := sm.FireEvent(StateA, Event1, testPayload{Value: "submitted"}); err != nil || target != StateB {
+		t.Errorf("Expected B, got %v: %v", target, err)
+	}
+
+	if info := sm.Transitions(StateA); len(info) != 1 || info[0].Condition != "submitted && reviewer" {
+		t.Errorf("Unexpected transition descriptors %+v", info)
+	}
+	if output := sm.ShowStateMachine(); !strings.Contains(output, "A --Event1(EXTERNAL)--> B [submitted && reviewer]") {
+		t.Errorf("Expected the condition in ShowStateMachine output, got:\n%s", output)
+	}
+	if diagram := sm.GenerateDiagram(PlantUML); !strings.Contains(diagram, "A --> B : Event1 [submitted && reviewer]") {
+		t.Errorf("Expected the condition in the diagram, got:\n%s", diagram)
+	}
+
+	explanation := sm.Explain(StateA, Event1, testPayload{})
+	if len(explanation.Candidates) != 1 || explanation.Candidates[0].Reason != "submitted not satisfied" {
+		t.Errorf("Expected the explanation to name the failing condition, got %+v", explanation.Candidates)
+	}
+}
@@ -0,0 +1,245 @@
+package fsm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock tells the time, replaceable in tests
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+// Now implements Clock interface
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After implements Clock interface
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the default Clock, backed by the time package
+var SystemClock Clock = systemClock{}
+
+// TransitionContext gives contextual conditions and actions access to the firing they run in
+// It embeds the context passed to FireEventContext, so it can be handed to context-aware code directly
+type TransitionContext[S comparable, E comparable] struct {
+	context.Context
+
+	// MachineId is the id of the firing state machine
+	MachineId string
+	// Transition describes the transition being evaluated or executed, including its name and metadata
+	Transition TransitionInfo[S, E]
+	// Parallel reports whether the transition runs as a branch of FireParallelEvent
+	Parallel bool
+	// Attempt is the 1-based number of the current execution attempt
+	Attempt int
+	// Clock is the clock of the state machine
+	Clock Clock
+
+	scope *firingScope[E]
+}
+
+// Now returns the current time of the state machine's clock
+func (tc *TransitionContext[S, E]) Now() time.Time {
+	return tc.Clock.Now()
+}
+
+// Set stashes a value that later conditions and actions of the same firing can read
+func (tc *TransitionContext[S, E]) Set(key string, value interface{}) {
+	tc.scope.mutex.Lock()
+	defer tc.scope.mutex.Unlock()
+
+	if tc.scope.values == nil {
+		tc.scope.values = make(map[string]interface{})
+	}
+	tc.scope.values[key] = value
+}
+
+// Get returns a value stashed earlier in the same firing
+func (tc *TransitionContext[S, E]) Get(key string) (interface{}, bool) {
+	tc.scope.mutex.Lock()
+	defer tc.scope.mutex.Unlock()
+
+	value, ok := tc.scope.values[key]
+	return value, ok
+}
+
+// Raise records a follow-up event, reported in Result.Raised
+// The event is not fired automatically, the caller decides whether and from which state to fire it
+func (tc *TransitionContext[S, E]) Raise(event E) {
+	tc.scope.mutex.Lock()
+	defer tc.scope.mutex.Unlock()
+
+	tc.scope.raised = append(tc.scope.raised, event)
+}
+
+// firingScope holds the values shared by the transitions of one firing
+type firingScope[E comparable] struct {
+	values map[string]interface{}
+	raised []E
+	mutex  sync.Mutex
+}
+
+// ContextualCondition is a condition that receives the transition context
+// Conditions implementing it are evaluated through IsSatisfiedContext while firing
+type ContextualCondition[S comparable, E comparable, P any] interface {
+	// IsSatisfiedContext returns true if the condition is met
+	IsSatisfiedContext(tc *TransitionContext[S, E], payload P) bool
+}
+
+// ContextualAction is an action that receives the transition context
+// Actions implementing it are executed through ExecuteContext while firing
+type ContextualAction[S comparable, E comparable, P any] interface {
+	// ExecuteContext runs the action during a state transition
+	ExecuteContext(tc *TransitionContext[S, E], payload P) error
+}
+
+// ContextualConditionFunc is a function type that implements both Condition and ContextualCondition interfaces,
+// so it can be passed to When
+type ContextualConditionFunc[S comparable, E comparable, P any] func(tc *TransitionContext[S, E], payload P) bool
+
+// IsSatisfiedContext implements ContextualCondition interface
+func (f ContextualConditionFunc[S, E, P]) IsSatisfiedContext(tc *TransitionContext[S, E], payload P) bool {
+	return f(tc, payload)
+}
+
+// IsSatisfied implements Condition interface, used outside of a firing with a context that only carries the clock
+func (f ContextualConditionFunc[S, E, P]) IsSatisfied(payload P) bool {
+	return f(detachedContext[S, E](TransitionInfo[S, E]{}), payload)
+}
+
+// ContextualActionFunc is a function type that implements both Action and ContextualAction interfaces,
+// so it can be passed to Perform
+type ContextualActionFunc[S comparable, E comparable, P any] func(tc *TransitionContext[S, E], payload P) error
+
+// ExecuteContext implements ContextualAction interface
+func (f ContextualActionFunc[S, E, P]) ExecuteContext(tc *TransitionContext[S, E], payload P) error {
+	return f(tc, payload)
+}
+
+// Execute implements Action interface, used outside of a firing with a context that only describes the transition
+func (f ContextualActionFunc[S, E, P]) Execute(from, to S, event E, payload P) error {
+	return f(detachedContext(TransitionInfo[S, E]{Source: from, Target: to, Event: event}), payload)
+}
+
+// AdaptCondition turns a plain condition into a ContextualCondition
+// Parameters:
+//
+//	condition: The condition to adapt
+//
+// Returns:
+//
+//	A contextual condition delegating to condition and ignoring the context
+func AdaptCondition[S comparable, E comparable, P any](condition Condition[P]) ContextualCondition[S, E, P] {
+	return ContextualConditionFunc[S, E, P](func(tc *TransitionContext[S, E], payload P) bool {
+		return condition.IsSatisfied(payload)
+	})
+}
+
+// AdaptAction turns a plain action into a ContextualAction
+// Parameters:
+//
+//	action: The action to adapt
+//
+// Returns:
+//
+//	A contextual action delegating to action with the states and event of the context
+func AdaptAction[S comparable, E comparable, P any](action Action[S, E, P]) ContextualAction[S, E, P] {
+	return ContextualActionFunc[S, E, P](func(tc *TransitionContext[S, E], payload P) error {
+		return action.Execute(tc.Transition.Source, tc.Transition.Target, tc.Transition.Event, payload)
+	})
+}
+
+// detachedContext creates a transition context for calls made outside of a firing
+func detachedContext[S comparable, E comparable](info TransitionInfo[S, E]) *TransitionContext[S, E] {
+	return &TransitionContext[S, E]{
+		Context:    context.Background(),
+		Transition: info,
+		Attempt:    1,
+		Clock:      SystemClock,
+		scope:      &firingScope[E]{},
+	}
+}
+
+// currentClock returns the clock of the state machine, SystemClock unless WithClock was used
+func (sm *StateMachineImpl[S, E, P]) currentClock() Clock {
+	if sm.clock == nil {
+		return SystemClock
+	}
+	return sm.clock
+}
+
+// firing carries the per-call state of one FireEvent or FireParallelEvent call
+type firing[S comparable, E comparable] struct {
+	ctx      context.Context
+	parallel bool
+	result   *Result[S, E]
+	scope    *firingScope[E] // created on first use by a contextual condition or action
+}
+
+// transitionContext creates the context handed to a contextual condition or action
+// f is nil when the condition is evaluated outside of a firing, for example by Simulate
+func (sm *StateMachineImpl[S, E, P]) transitionContext(f *firing[S, E], transition *Transition[S, E, P]) *TransitionContext[S, E] {
+	tc := detachedContext(transition.Info())
+	tc.MachineId = sm.id
+	tc.Clock = sm.currentClock()
+	if f != nil {
+		if f.scope == nil {
+			f.scope = tc.scope
+		}
+		tc.Context = f.ctx
+		tc.Parallel = f.parallel
+		tc.scope = f.scope
+	}
+	return tc
+}
+
+// isSatisfied evaluates a non-nil condition, passing the transition context to contextual conditions,
+// including those combined with And, Or, Not and Named
+func (sm *StateMachineImpl[S, E, P]) isSatisfied(f *firing[S, E], transition *Transition[S, E, P], payload P) bool {
+	return evaluateCondition(transition.Condition, sm.evaluator(f, transition, payload))
+}
+
+// evaluator returns the evaluator of the conditions that are not combined, passing the transition context,
+// created on first use, to contextual conditions
+func (sm *StateMachineImpl[S, E, P]) evaluator(f *firing[S, E], transition *Transition[S, E, P], payload P) func(condition Condition[P]) bool {
+	var tc *TransitionContext[S, E]
+	return func(condition Condition[P]) bool {
+		if contextual, ok := condition.(ContextualCondition[S, E, P]); ok {
+			if tc == nil {
+				tc = sm.transitionContext(f, transition)
+			}
+			return contextual.IsSatisfiedContext(tc, payload)
+		}
+		return condition.IsSatisfied(payload)
+	}
+}
+
+// execute runs the action of a transition once, passing the transition context with ctx to contextual actions
+// Chains resume after the actions that succeeded in earlier attempts of run. The error of the action is returned as is
+func (sm *StateMachineImpl[S, E, P]) execute(f *firing[S, E], ctx context.Context, transition *Transition[S, E, P], payload P, attempt int, run *actionRun) error {
+	switch action := transition.Action.(type) {
+	case nil:
+		return nil
+	case actionChain[S, E, P]:
+		return sm.executeChain(f, ctx, transition, action, payload, attempt, run)
+	case ContextualAction[S, E, P]:
+		tc := sm.transitionContext(f, transition)
+		tc.Context = ctx
+		tc.Attempt = attempt
+		return action.ExecuteContext(tc, payload)
+	default:
+		return action.Execute(transition.Source.GetID(), transition.Target.GetID(), transition.Event, payload)
+	}
+}
@@ -0,0 +1,138 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fixedClock is a Clock that always returns the same time
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.now.Add(d)
+	return ch
+}
+
+type ctxKey struct{}
+
+// TestTransitionContext tests that contextual conditions and actions see the firing they run in
+func TestTransitionContext(t *testing.T) {
+	clock := fixedClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	var seen []*TransitionContext[testState, testEvent]
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithClock(clock)
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC).
+		On(Event1).
+		When(ContextualConditionFunc[testState, testEvent, testPayload](func(tc *TransitionContext[testState, testEvent], payload testPayload) bool {
+			return tc.MachineId == "TransitionContextTest"
+		})).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			seen = append(seen, tc)
+			if previous, ok := tc.Get("first"); ok {
+				tc.Set("second", previous)
+				tc.Raise(Event2)
+			} else {
+				tc.Set("first", tc.Transition.Target)
+			}
+			return nil
+		})).
+		Name("fan-out").
+		Metadata("owner", "ops")
+
+	sm, err := builder.Build("TransitionContextTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	result, err := sm.FireParallel(StateA, Event1, testPayload{})
+	if err != nil {
+		t.Fatalf("Failed to fire parallel event: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("Expected 2 action calls, got %d", len(seen))
+	}
+	for _, tc := range seen {
+		if tc.MachineId != "TransitionContextTest" || !tc.Parallel || tc.Attempt != 1 {
+			t.Errorf("Unexpected context: %+v", tc)
+		}
+		if tc.Transition.Name != "fan-out" || tc.Transition.Metadata["owner"] != "ops" {
+			t.Errorf("Expected transition name and metadata, got %+v", tc.Transition)
+		}
+		if !tc.Now().Equal(clock.now) {
+			t.Errorf("Expected clock time %v, got %v", clock.now, tc.Now())
+		}
+	}
+	if value, ok := seen[1].Get("second"); !ok || value != StateB {
+		t.Errorf("Expected the second branch to read the value stashed by the first, got %v", value)
+	}
+	if len(result.Raised) != 1 || result.Raised[0] != Event2 {
+		t.Errorf("Expected raised [Event2], got %v", result.Raised)
+	}
+	if result.Transitions[0].Name != "fan-out" {
+		t.Errorf("Expected named transition in result, got %+v", result.Transitions[0])
+	}
+}
+
+// TestTransitionContextCarriesContext tests that the context passed to FireEventContext reaches actions
+func TestTransitionContextCarriesContext(t *testing.T) {
+	var value interface{}
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			value = tc.Value(ctxKey{})
+			if tc.Parallel {
+				t.Error("Expected a single transition not to be flagged parallel")
+			}
+			return nil
+		}))
+
+	sm, err := builder.Build("TransitionContextValueTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-42")
+	if _, err := sm.FireEventContext(ctx, StateA, Event1, testPayload{}); err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	if value != "request-42" {
+		t.Errorf("Expected context value request-42, got %v", value)
+	}
+}
+
+// TestAdapters tests that plain conditions and actions can be used where contextual ones are expected
+func TestAdapters(t *testing.T) {
+	var executed []testState
+	action := AdaptAction[testState, testEvent, testPayload](ActionFunc[testState, testEvent, testPayload](
+		func(from, to testState, event testEvent, payload testPayload) error {
+			executed = append(executed, from, to)
+			return nil
+		}))
+	condition := AdaptCondition[testState, testEvent, testPayload](&alwaysTrueCondition{})
+
+	tc := detachedContext(TransitionInfo[testState, testEvent]{Source: StateA, Target: StateB, Event: Event1})
+	if !condition.IsSatisfiedContext(tc, testPayload{}) {
+		t.Error("Expected adapted condition to be satisfied")
+	}
+	if err := action.ExecuteContext(tc, testPayload{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(executed) != 2 || executed[0] != StateA || executed[1] != StateB {
+		t.Errorf("Expected adapted action to receive A -> B, got %v", executed)
+	}
+}
@@ -0,0 +1,349 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// Definition describes a state machine in a serializable form, typically loaded from a JSON file
+type Definition struct {
+	// ID is the state machine ID passed to Build
+	ID string `json:"id"`
+	// Package is the Go package generated code belongs to
+	Package string `json:"package,omitempty"`
+	// StateType is the name of the generated Go state type
+	StateType string `json:"stateType,omitempty"`
+	// EventType is the name of the generated Go event type
+	EventType string `json:"eventType,omitempty"`
+	// PayloadType is the Go payload type, defaults to any
+	PayloadType string `json:"payloadType,omitempty"`
+	// States lists every state of the machine
+	States []ConstantDefinition `json:"states"`
+	// Events lists every event of the machine
+	Events []ConstantDefinition `json:"events"`
+	// Transitions lists the transitions between states
+	Transitions []TransitionDefinition `json:"transitions"`
+}
+
+// ConstantDefinition names a state or event
+type ConstantDefinition struct {
+	// Name is the Go identifier of the constant
+	Name string `json:"name"`
+	// Value is the underlying string value, defaults to Name
+	Value string `json:"value,omitempty"`
+}
+
+// TransitionDefinition describes one transition statement of a definition
+type TransitionDefinition struct {
+	// Kind is "external" (default), "internal" or "parallel"
+	Kind string `json:"kind,omitempty"`
+	// From lists the source state names; several sources declare one transition per source
+	From []string `json:"from"`
+	// To lists the target state names; several targets are only allowed for parallel transitions
+	To []string `json:"to,omitempty"`
+	// On is the triggering event name
+	On string `json:"on"`
+	// When is the optional name of the guarding condition
+	When string `json:"when,omitempty"`
+	// Guard is an optional expression the payload must satisfy as well, see CompileExpression
+	Guard string `json:"guard,omitempty"`
+	// Perform is the optional name of the action
+	Perform string `json:"perform,omitempty"`
+}
+
+// Transition kinds accepted in definitions
+const (
+	KindExternal = "external"
+	KindInternal = "internal"
+	KindParallel = "parallel"
+)
+
+// DefinitionError lists every problem found while validating a definition
+type DefinitionError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *DefinitionError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidDefinition, strings.Join(e.Problems, "; "))
+}
+
+// Is reports whether target is ErrInvalidDefinition
+func (e *DefinitionError) Is(target error) bool {
+	return target == ErrInvalidDefinition
+}
+
+// LoadDefinition reads and validates a JSON definition file
+// Parameters:
+//
+//	path: Path of the definition file
+//
+// Returns:
+//
+//	The parsed definition and possible error
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDefinition(data)
+}
+
+// ParseDefinition decodes and validates a JSON definition
+// Parameters:
+//
+//	data: JSON encoded definition
+//
+// Returns:
+//
+//	The parsed definition and possible error
+func ParseDefinition(data []byte) (*Definition, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var definition Definition
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// StateValue returns the value of the named state, or false if the state is not defined
+func (d *Definition) StateValue(name string) (string, bool) {
+	return lookupConstant(d.States, name)
+}
+
+// EventValue returns the value of the named event, or false if the event is not defined
+func (d *Definition) EventValue(name string) (string, bool) {
+	return lookupConstant(d.Events, name)
+}
+
+// Validate checks the definition for structural errors
+// Returns:
+//
+//	A *DefinitionError listing all problems, or nil if the definition is valid
+func (d *Definition) Validate() error {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if d.ID == "" {
+		report("missing machine id")
+	}
+	for _, typeName := range []string{d.StateType, d.EventType} {
+		if typeName != "" && !token.IsIdentifier(typeName) {
+			report("type name %q is not a valid Go identifier", typeName)
+		}
+	}
+
+	// State and event names share one namespace, generated code declares them as constants of the same package
+	declared := make(map[string]string)
+	validateConstants := func(kind string, constants []ConstantDefinition) {
+		if len(constants) == 0 {
+			report("no %ss defined", kind)
+		}
+		values := make(map[string]bool)
+		for _, constant := range constants {
+			if !token.IsIdentifier(constant.Name) {
+				report("%s name %q is not a valid Go identifier", kind, constant.Name)
+			}
+			value := constantValue(constant)
+			other, ok := declared[constant.Name]
+			if ok && other == kind {
+				report("duplicate %s name %q", kind, constant.Name)
+			} else if ok {
+				report("%s name %q is already used by a %s", kind, constant.Name, other)
+			} else if values[value] {
+				report("duplicate %s value %q", kind, value)
+			}
+			if !ok {
+				declared[constant.Name] = kind
+			}
+			values[value] = true
+		}
+	}
+	validateConstants("state", d.States)
+	validateConstants("event", d.Events)
+
+	for i, transition := range d.Transitions {
+		position := fmt.Sprintf("transition %d", i+1)
+
+		for _, name := range append(append([]string{}, transition.From...), transition.To...) {
+			if _, ok := d.StateValue(name); !ok {
+				report("%s: unknown state %q", position, name)
+			}
+		}
+		if _, ok := d.EventValue(transition.On); !ok {
+			report("%s: unknown event %q", position, transition.On)
+		}
+		if len(transition.From) == 0 {
+			report("%s: missing source state", position)
+		}
+		if transition.Guard != "" {
+			if _, err := parseExpression(transition.Guard); err != nil {
+				report("%s: %v", position, err)
+			}
+		}
+
+		switch transition.Kind {
+		case "", KindExternal:
+			if len(transition.To) != 1 {
+				report("%s: external transitions need exactly one target state", position)
+			}
+		case KindInternal:
+			if len(transition.From) != 1 {
+				report("%s: internal transitions need exactly one state", position)
+			} else if len(transition.To) > 1 || (len(transition.To) == 1 && transition.To[0] != transition.From[0]) {
+				report("%s: %v", position, ErrInternalTransition)
+			}
+		case KindParallel:
+			if len(transition.From) != 1 {
+				report("%s: parallel transitions need exactly one source state", position)
+			}
+			if len(transition.To) == 0 {
+				report("%s: parallel transitions need at least one target state", position)
+			}
+		default:
+			report("%s: unknown kind %q", position, transition.Kind)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &DefinitionError{Problems: problems}
+	}
+	return nil
+}
+
+// ImportDefinition loads the states and transitions of a definition into a new builder
+// States and events are identified by their values; condition and action names are resolved through the registry,
+// and guard expressions are compiled against the payload type
+// Parameters:
+//
+//	definition: The definition to load
+//	options: Name resolution options
+//
+// Returns:
+//
+//	A builder holding the defined transitions and possible error
+func ImportDefinition[S comparable, E comparable, P any](definition *Definition, options ImportOptions[S, E, P]) (*StateMachineBuilder[S, E, P], error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	parseState := options.ParseState
+	if parseState == nil {
+		parseState = parseText[S]
+	}
+	parseEvent := options.ParseEvent
+	if parseEvent == nil {
+		parseEvent = parseText[E]
+	}
+
+	builder := NewStateMachineBuilder[S, E, P]()
+	stateMachine := builder.stateMachine
+
+	states := make(map[string]*State[S, E, P])
+	for _, constant := range definition.States {
+		stateId, err := parseState(constantValue(constant))
+		if err != nil {
+			return nil, fmt.Errorf("%w: state %q: %v", ErrInvalidDefinition, constant.Name, err)
+		}
+		states[constant.Name] = stateMachine.GetState(stateId)
+	}
+
+	for i, transition := range definition.Transitions {
+		value, _ := definition.EventValue(transition.On)
+		event, err := parseEvent(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: event %q: %v", ErrInvalidDefinition, transition.On, err)
+		}
+
+		var condition Condition[P]
+		if transition.When != "" {
+			if condition, err = resolveCondition(options.Registry, transition.When); err != nil {
+				return nil, fmt.Errorf("transition %d: %w", i+1, err)
+			}
+		}
+		if transition.Guard != "" {
+			guard, err := options.parseGuard(transition.Guard)
+			if err != nil {
+				return nil, fmt.Errorf("transition %d: %w", i+1, err)
+			}
+			condition = addGuard(condition, guard)
+		}
+
+		var action Action[S, E, P]
+		if transition.Perform != "" {
+			if action, err = resolveAction(options.Registry, transition.Perform); err != nil {
+				return nil, fmt.Errorf("transition %d: %w", i+1, err)
+			}
+		}
+
+		transType := External
+		targets := transition.To
+		if transition.Kind == KindInternal {
+			transType = Internal
+			targets = transition.From
+		}
+
+		for _, sourceName := range transition.From {
+			for _, targetName := range targets {
+				created := states[sourceName].AddTransition(event, states[targetName], transType)
+				created.Condition = condition
+				created.Action = action
+			}
+		}
+	}
+
+	return builder, nil
+}
+
+// ConditionNames returns the distinct condition names used by the definition in order of first use
+func (d *Definition) ConditionNames() []string {
+	return d.collectNames(func(t TransitionDefinition) string { return t.When })
+}
+
+// ActionNames returns the distinct action names used by the definition in order of first use
+func (d *Definition) ActionNames() []string {
+	return d.collectNames(func(t TransitionDefinition) string { return t.Perform })
+}
+
+// collectNames returns the distinct non-empty names selected from the transitions
+func (d *Definition) collectNames(selectName func(TransitionDefinition) string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, transition := range d.Transitions {
+		name := selectName(transition)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lookupConstant finds the value of a named constant
+func lookupConstant(constants []ConstantDefinition, name string) (string, bool) {
+	for _, constant := range constants {
+		if constant.Name == name {
+			return constantValue(constant), true
+		}
+	}
+	return "", false
+}
+
+// constantValue returns the value of a constant, defaulting to its name
+func constantValue(constant ConstantDefinition) string {
+	if constant.Value == "" {
+		return constant.Name
+	}
+	return constant.Value
+}
@@ -0,0 +1,56 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseDefinitionValidation tests that every structural problem of a definition is reported
+func TestParseDefinitionValidation(t *testing.T) {
+	_, err := ParseDefinition([]byte(`{
+		"id": "Broken",
+		"states": [{"name": "A"}, {"name": "A"}, {"name": "B"}],
+		"events": [{"name": "Go"}],
+		"transitions": [
+			{"from": ["A"], "to": ["C"], "on": "Go"},
+			{"kind": "internal", "from": ["A"], "to": ["B"], "on": "Go"},
+			{"from": ["A"], "to": ["B"], "on": "Stop"}
+		]
+	}`))
+
+	var definitionErr *DefinitionError
+	if !errors.As(err, &definitionErr) || !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatalf("Expected a DefinitionError, got %v", err)
+	}
+	if len(definitionErr.Problems) != 4 {
+		t.Errorf("Expected 4 problems, got %d: %v", len(definitionErr.Problems), definitionErr.Problems)
+	}
+}
+
+// TestParseDefinitionSharedName tests that a state and an event cannot share a name
+func TestParseDefinitionSharedName(t *testing.T) {
+	_, err := ParseDefinition([]byte(`{
+		"id": "SharedName",
+		"states": [{"name": "Pending"}, {"name": "Paid"}],
+		"events": [{"name": "Paid", "value": "pay"}],
+		"transitions": [
+			{"from": ["Pending"], "to": ["Paid"], "on": "Paid"}
+		]
+	}`))
+
+	var definitionErr *DefinitionError
+	if !errors.As(err, &definitionErr) {
+		t.Fatalf("Expected a DefinitionError, got %v", err)
+	}
+	if len(definitionErr.Problems) != 1 || definitionErr.Problems[0] != `event name "Paid" is already used by a state` {
+		t.Errorf("Expected the shared name to be reported, got %v", definitionErr.Problems)
+	}
+}
+
+// TestParseDefinitionUnknownField tests that misspelled fields are rejected
+func TestParseDefinitionUnknownField(t *testing.T) {
+	_, err := ParseDefinition([]byte(`{"id": "M", "states": [], "events": [], "transitoins": []}`))
+	if !errors.Is(err, ErrInvalidDefinition) {
+		t.Errorf("Expected ErrInvalidDefinition, got %v", err)
+	}
+}
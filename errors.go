@@ -11,7 +11,17 @@ var (
 	ErrStateNotFound            = errors.New("state not found")
 	ErrTransitionNotFound       = errors.New("no transition found")
 	ErrConditionNotMet          = errors.New("transition conditions not met")
+	ErrAmbiguousTransition      = errors.New("more than one transition is satisfied")
 	ErrActionExecutionFailed    = errors.New("action execution failed")
+	ErrActionTimeout            = errors.New("action timed out")
 	ErrStateMachineNotReady     = errors.New("state machine is not ready yet")
 	ErrInternalTransition       = errors.New("internal transition source and target states must be the same")
+	ErrUnsupportedDiagramFormat = errors.New("unsupported diagram format")
+	ErrInvalidDiagram           = errors.New("invalid diagram")
+	ErrConditionNotRegistered   = errors.New("condition not registered")
+	ErrActionNotRegistered      = errors.New("action not registered")
+	ErrUnsupportedType          = errors.New("no conversion from text available for type")
+	ErrInvalidDefinition        = errors.New("invalid state machine definition")
+	ErrInvalidExpression        = errors.New("invalid expression")
+	ErrMissingFallbackHandler   = errors.New("unhandled event fallback policy requires a handler")
 )
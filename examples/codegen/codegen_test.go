@@ -0,0 +1,82 @@
+package codegen
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// orderHandlers implements the generated condition and action interfaces
+type orderHandlers struct {
+	log []string
+}
+
+func (h *orderHandlers) HasAmount(payload OrderPayload) bool {
+	return payload.Amount > 0
+}
+
+func (h *orderHandlers) Charge(from, to OrderState, event OrderEvent, payload OrderPayload) error {
+	h.log = append(h.log, "charge "+payload.OrderID)
+	return nil
+}
+
+func (h *orderHandlers) Ship(from, to OrderState, event OrderEvent, payload OrderPayload) error {
+	h.log = append(h.log, "ship "+payload.OrderID)
+	return nil
+}
+
+func (h *orderHandlers) Refund(from, to OrderState, event OrderEvent, payload OrderPayload) error {
+	h.log = append(h.log, "refund "+payload.OrderID)
+	return nil
+}
+
+// TestGeneratedStateMachine tests the state machine built by the generated code
+func TestGeneratedStateMachine(t *testing.T) {
+	handlers := &orderHandlers{}
+	stateMachine, err := BuildGeneratedOrderStateMachine(handlers, handlers)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	payload := OrderPayload{OrderID: "ORD-1", Amount: 10}
+	state := OrderCreated
+	for _, event := range []OrderEvent{EventPay, EventShip, EventDeliver} {
+		state, err = stateMachine.FireEvent(state, event, payload)
+		if err != nil {
+			t.Fatalf("Failed to fire %s: %v", event, err)
+		}
+	}
+
+	if state != OrderDelivered {
+		t.Errorf("Expected state to be %s, got %s", OrderDelivered, state)
+	}
+	if len(handlers.log) != 2 {
+		t.Errorf("Expected 2 actions to be logged, got %d", len(handlers.log))
+	}
+
+	if _, err := stateMachine.FireEvent(OrderCreated, EventPay, OrderPayload{}); err == nil {
+		t.Error("Expected zero amount payment to be rejected")
+	}
+}
+
+// TestGeneratedEnums tests the generated text marshaling and enumeration helpers
+func TestGeneratedEnums(t *testing.T) {
+	if len(AllOrderStates()) != 5 || len(AllOrderEvents()) != 4 {
+		t.Fatalf("Unexpected enumeration sizes: %d states, %d events", len(AllOrderStates()), len(AllOrderEvents()))
+	}
+
+	data, err := json.Marshal(map[OrderState]OrderEvent{OrderPaid: EventShip})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(data) != `{"PAID":"SHIP"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	var state OrderState
+	if err := json.Unmarshal([]byte(`"SHIPPED"`), &state); err != nil || state != OrderShipped {
+		t.Errorf("Expected %s, got %s (%v)", OrderShipped, state, err)
+	}
+	if err := json.Unmarshal([]byte(`"LOST"`), &state); err == nil {
+		t.Error("Expected unknown state to be rejected")
+	}
+}
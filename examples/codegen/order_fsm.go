@@ -0,0 +1,151 @@
+// Code generated by fsmgen from order.fsm.json. DO NOT EDIT.
+
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// OrderState enumerates the states of the GeneratedOrderStateMachine state machine
+type OrderState string
+
+const (
+	OrderCreated   OrderState = "CREATED"
+	OrderPaid      OrderState = "PAID"
+	OrderShipped   OrderState = "SHIPPED"
+	OrderDelivered OrderState = "DELIVERED"
+	OrderCancelled OrderState = "CANCELLED"
+)
+
+// AllOrderStates returns every OrderState in declaration order
+func AllOrderStates() []OrderState {
+	return []OrderState{OrderCreated, OrderPaid, OrderShipped, OrderDelivered, OrderCancelled}
+}
+
+// String implements fmt.Stringer
+func (s OrderState) String() string {
+	return string(s)
+}
+
+// IsValid reports whether s is a declared OrderState
+func (s OrderState) IsValid() bool {
+	switch s {
+	case OrderCreated, OrderPaid, OrderShipped, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s OrderState) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("unknown OrderState %q", string(s))
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *OrderState) UnmarshalText(text []byte) error {
+	value := OrderState(text)
+	if !value.IsValid() {
+		return fmt.Errorf("unknown OrderState %q", string(text))
+	}
+	*s = value
+	return nil
+}
+
+// OrderEvent enumerates the events of the GeneratedOrderStateMachine state machine
+type OrderEvent string
+
+const (
+	EventPay     OrderEvent = "PAY"
+	EventShip    OrderEvent = "SHIP"
+	EventDeliver OrderEvent = "DELIVER"
+	EventCancel  OrderEvent = "CANCEL"
+)
+
+// AllOrderEvents returns every OrderEvent in declaration order
+func AllOrderEvents() []OrderEvent {
+	return []OrderEvent{EventPay, EventShip, EventDeliver, EventCancel}
+}
+
+// String implements fmt.Stringer
+func (e OrderEvent) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is a declared OrderEvent
+func (e OrderEvent) IsValid() bool {
+	switch e {
+	case EventPay, EventShip, EventDeliver, EventCancel:
+		return true
+	}
+	return false
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (e OrderEvent) MarshalText() ([]byte, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("unknown OrderEvent %q", string(e))
+	}
+	return []byte(e), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (e *OrderEvent) UnmarshalText(text []byte) error {
+	value := OrderEvent(text)
+	if !value.IsValid() {
+		return fmt.Errorf("unknown OrderEvent %q", string(text))
+	}
+	*e = value
+	return nil
+}
+
+// GeneratedOrderStateMachineConditions declares the named conditions used by the GeneratedOrderStateMachine state machine
+type GeneratedOrderStateMachineConditions interface {
+	HasAmount(payload OrderPayload) bool
+}
+
+// GeneratedOrderStateMachineActions declares the named actions used by the GeneratedOrderStateMachine state machine
+type GeneratedOrderStateMachineActions interface {
+	Charge(from, to OrderState, event OrderEvent, payload OrderPayload) error
+	Ship(from, to OrderState, event OrderEvent, payload OrderPayload) error
+	Refund(from, to OrderState, event OrderEvent, payload OrderPayload) error
+}
+
+// BuildGeneratedOrderStateMachine builds and registers the GeneratedOrderStateMachine state machine
+func BuildGeneratedOrderStateMachine(conditions GeneratedOrderStateMachineConditions, actions GeneratedOrderStateMachineActions) (fsm.StateMachine[OrderState, OrderEvent, OrderPayload], error) {
+	builder := fsm.NewStateMachineBuilder[OrderState, OrderEvent, OrderPayload]()
+	noop := func(from, to OrderState, event OrderEvent, payload OrderPayload) error {
+		return nil
+	}
+
+	builder.ExternalTransition().
+		From(OrderCreated).
+		To(OrderPaid).
+		On(EventPay).
+		WhenFunc(conditions.HasAmount).
+		PerformFunc(actions.Charge)
+
+	builder.ExternalTransition().
+		From(OrderPaid).
+		To(OrderShipped).
+		On(EventShip).
+		PerformFunc(actions.Ship)
+
+	builder.ExternalTransition().
+		From(OrderShipped).
+		To(OrderDelivered).
+		On(EventDeliver).
+		PerformFunc(noop)
+
+	builder.ExternalTransitions().
+		FromAmong(OrderCreated, OrderPaid, OrderShipped).
+		To(OrderCancelled).
+		On(EventCancel).
+		PerformFunc(actions.Refund)
+
+	return builder.Build("GeneratedOrderStateMachine")
+}
@@ -0,0 +1,10 @@
+// Package codegen shows a state machine whose states, events and builder code are generated by fsmgen
+package codegen
+
+//go:generate go run github.com/lingcoder/fsm-go/cmd/fsmgen -in order.fsm.json
+
+// OrderPayload carries the order data through transitions
+type OrderPayload struct {
+	OrderID string
+	Amount  float64
+}
@@ -0,0 +1,159 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation describes how an event would be handled from a state
+type Explanation[S comparable, E comparable] struct {
+	// Source is the state the event was explained from
+	Source S
+	// Event is the explained event
+	Event E
+	// Candidates lists every transition for the event in evaluation order
+	Candidates []CandidateExplanation[S, E]
+	// Winner is the transition FireEvent would take, nil if none
+	Winner *TransitionInfo[S, E]
+	// Err is the error FireEvent would return, nil if a transition would be taken
+	Err error
+}
+
+// CandidateExplanation records the condition result of one candidate transition
+type CandidateExplanation[S comparable, E comparable] struct {
+	// Transition describes the candidate
+	Transition TransitionInfo[S, E]
+	// Satisfied reports whether the condition passed (true when there is no condition)
+	Satisfied bool
+	// Reason is a human-readable explanation of the condition result
+	Reason string
+}
+
+// Explain evaluates every candidate transition for an event and reports each condition's result
+// Conditions implementing ExplainingCondition contribute their own reasons
+// Parameters:
+//
+//	sourceStateId: The state to explain from
+//	event: The event to explain
+//	payload: The payload conditions are evaluated against
+//
+// Returns:
+//
+//	The explanation, with Err set to the error FireEvent would return
+func (sm *StateMachineImpl[S, E, P]) Explain(sourceStateId S, event E, payload P) Explanation[S, E] {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	explanation := Explanation[S, E]{
+		Source: sourceStateId,
+		Event:  event,
+	}
+
+	if !sm.ready {
+		explanation.Err = ErrStateMachineNotReady
+		return explanation
+	}
+
+	sourceState, ok := sm.stateMap[sourceStateId]
+	if !ok {
+		explanation.Err = ErrStateNotFound
+		return explanation
+	}
+
+	transitions := sourceState.GetEventTransitions(event)
+	if len(transitions) == 0 {
+		explanation.Err = ErrTransitionNotFound
+		return explanation
+	}
+
+	var winner *Transition[S, E, P]
+	for _, transition := range transitions {
+		satisfied, reason := sm.explainCondition(transition, payload)
+		candidate := CandidateExplanation[S, E]{
+			Transition: transition.Info(),
+			Satisfied:  satisfied,
+			Reason:     reason,
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+
+		switch {
+		case !satisfied:
+		case winner == nil:
+			winner = transition
+			info := candidate.Transition
+			explanation.Winner = &info
+		case explanation.Err == nil && sm.contends(winner, transition):
+			explanation.Err = ambiguityError(winner, transition)
+		}
+	}
+
+	if explanation.Err != nil {
+		explanation.Winner = nil
+	} else if explanation.Winner == nil {
+		explanation.Err = ErrConditionNotMet
+	}
+	return explanation
+}
+
+// explainCondition evaluates a condition and describes the result
+// Operands of And, Or, Not and Named are evaluated like when firing, so contextual operands get the transition context
+func (sm *StateMachineImpl[S, E, P]) explainCondition(transition *Transition[S, E, P], payload P) (bool, string) {
+	condition := transition.Condition
+	if condition == nil {
+		return true, "no condition"
+	}
+
+	satisfied, reason := explainWith(condition, payload, sm.evaluator(nil, transition, payload))
+	if reason == "" {
+		reason = defaultConditionReason(satisfied)
+	}
+	return satisfied, reason
+}
+
+// defaultConditionReason describes a condition result when the condition gives no reason
+func defaultConditionReason(satisfied bool) string {
+	if satisfied {
+		return "condition satisfied"
+	}
+	return "condition not satisfied"
+}
+
+// String renders the explanation as human-readable text
+func (e Explanation[S, E]) String() string {
+	var sb strings.Builder
+
+	if e.Err != nil {
+		sb.WriteString(fmt.Sprintf("%v from %v: rejected: %v\n", e.Event, e.Source, e.Err))
+	} else {
+		sb.WriteString(fmt.Sprintf("%v from %v: moves to %v\n", e.Event, e.Source, e.Winner.Target))
+	}
+
+	winnerIndex := e.winnerIndex()
+	for i, candidate := range e.Candidates {
+		marker := " "
+		if i == winnerIndex {
+			marker = "*"
+		}
+		result := "passed"
+		if !candidate.Satisfied {
+			result = "failed"
+		}
+		sb.WriteString(fmt.Sprintf("%s %d. %v -> %v: %s (%s)\n",
+			marker, i+1, candidate.Transition.Source, candidate.Transition.Target, result, candidate.Reason))
+	}
+
+	return sb.String()
+}
+
+// winnerIndex returns the index of the first satisfied candidate, or -1 when no transition would be taken
+func (e Explanation[S, E]) winnerIndex() int {
+	if e.Winner == nil {
+		return -1
+	}
+	for i, candidate := range e.Candidates {
+		if candidate.Satisfied {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestExplain tests that every candidate is reported with its condition result and reason
+func TestExplain(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(ExplainingConditionFunc[testPayload](func(payload testPayload) (bool, string) {
+			if payload.Value == "shipped" {
+				return false, "order already shipped"
+			}
+			return true, "order not shipped yet"
+		})).
+		Perform(&noopAction{})
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateC).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value == "vip"
+		}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("ExplainTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	explanation := sm.Explain(StateA, Event1, testPayload{Value: "shipped"})
+	if !errors.Is(explanation.Err, ErrConditionNotMet) || explanation.Winner != nil {
+		t.Fatalf("Expected rejection, got winner %v and error %v", explanation.Winner, explanation.Err)
+	}
+	if len(explanation.Candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(explanation.Candidates))
+	}
+	if explanation.Candidates[0].Reason != "order already shipped" {
+		t.Errorf("Expected custom reason, got %q", explanation.Candidates[0].Reason)
+	}
+	if explanation.Candidates[1].Reason != "condition not satisfied" {
+		t.Errorf("Expected default reason, got %q", explanation.Candidates[1].Reason)
+	}
+	if text := explanation.String(); !strings.Contains(text, "A -> B: failed (order already shipped)") {
+		t.Errorf("Unexpected explanation text:\n%s", text)
+	}
+
+	explanation = sm.Explain(StateA, Event1, testPayload{Value: "vip"})
+	if explanation.Err != nil || explanation.Winner == nil || explanation.Winner.Target != StateB {
+		t.Fatalf("Expected first candidate to win, got %+v", explanation)
+	}
+	if !explanation.Candidates[1].Satisfied {
+		t.Error("Expected every candidate to be evaluated")
+	}
+	if text := explanation.String(); !strings.Contains(text, "* 1. A -> B: passed") {
+		t.Errorf("Expected winner to be marked:\n%s", text)
+	}
+}
+
+// TestExplainContextualOperands tests that Explain passes the transition context to contextual operands like FireEvent
+func TestExplainContextualOperands(t *testing.T) {
+	machineCheck := ContextualConditionFunc[testState, testEvent, testPayload](
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) bool {
+			return tc.MachineId == "ExplainContextualOperandsTest"
+		})
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).
+		When(Named[testPayload]("machineCheck", machineCheck)).
+		Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateC).On(Event2).
+		When(And[testPayload](Not[testPayload](machineCheck), Always[testPayload]())).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("ExplainContextualOperandsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	explanation := sm.Explain(StateA, Event1, testPayload{})
+	if explanation.Err != nil || explanation.Winner == nil || explanation.Winner.Target != StateB {
+		t.Errorf("Expected Explain to select B like FireEvent, got %v %v", explanation.Winner, explanation.Err)
+	}
+	if reason := explanation.Candidates[0].Reason; reason != "machineCheck satisfied" {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected FireEvent to move to B, got %v %v", state, err)
+	}
+
+	explanation = sm.Explain(StateA, Event2, testPayload{})
+	if !errors.Is(explanation.Err, ErrConditionNotMet) || explanation.Candidates[0].Reason != "not (condition satisfied)" {
+		t.Errorf("Expected the negated contextual operand to fail, got %q %v", explanation.Candidates[0].Reason, explanation.Err)
+	}
+}
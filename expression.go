@@ -0,0 +1,620 @@
+package fsm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field reads a payload field for expressions, declared with StringField, NumberField or BoolField
+type Field[P any] struct {
+	compiled compiledExpression[P]
+}
+
+// StringField declares a string field read by get
+func StringField[P any](get func(payload P) string) Field[P] {
+	return Field[P]{compiled: compiledExpression[P]{valueType: stringValue, text: get}}
+}
+
+// NumberField declares a numeric field read by get
+func NumberField[P any](get func(payload P) float64) Field[P] {
+	return Field[P]{compiled: compiledExpression[P]{valueType: numberValue, number: get}}
+}
+
+// BoolField declares a boolean field read by get
+func BoolField[P any](get func(payload P) bool) Field[P] {
+	return Field[P]{compiled: compiledExpression[P]{valueType: boolValue, boolean: get}}
+}
+
+// ExpressionOptions configures how expressions read the payload
+type ExpressionOptions[P any] struct {
+	// Fields declares fields by their path without the "payload." prefix, for example "Customer.Tier"
+	// Declared fields take precedence over reflection, and are required when the payload type is an interface
+	Fields map[string]Field[P]
+}
+
+// CompileExpression compiles a guard expression into a condition
+// Expressions compare payload fields with literals, for example payload.Amount > 0 && payload.Reviewer != "".
+// They support the operators || && ! == != < <= > >=, parentheses, numbers, 'single' or "double" quoted strings,
+// true and false. Fields are read through reflection from exported struct fields and string-keyed maps,
+// following pointers, where a nil pointer or a missing key reads as the zero value.
+// Numbers of any Go numeric type compare as float64.
+// Parameters:
+//
+//	source: The expression
+//	options: Optional field declarations
+//
+// Returns:
+//
+//	The condition, described by the expression source, and an error wrapping ErrInvalidExpression
+//	when the expression is malformed or does not type-check against P
+func CompileExpression[P any](source string, options ...ExpressionOptions[P]) (Condition[P], error) {
+	node, err := parseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := expressionCompiler[P]{source: source}
+	if len(options) > 0 {
+		compiler.fields = options[0].Fields
+	}
+
+	expression := &expressionCondition[P]{source: source}
+	for _, conjunct := range conjuncts(node) {
+		compiled, err := compiler.compile(conjunct)
+		if err != nil {
+			return nil, err
+		}
+		if compiled.valueType != boolValue {
+			return nil, compiler.errorf(conjunct, "%s is a %s, not a condition", conjunct.text(source), compiled.valueType)
+		}
+		expression.conjuncts = append(expression.conjuncts, compiled.boolean)
+		expression.texts = append(expression.texts, conjunct.text(source))
+	}
+	return expression, nil
+}
+
+// expressionCondition is the condition returned by CompileExpression
+// The top-level operands of && are kept apart so that explanations can name the first one not satisfied
+type expressionCondition[P any] struct {
+	source    string
+	conjuncts []func(payload P) bool
+	texts     []string
+}
+
+// IsSatisfied implements Condition interface
+func (c *expressionCondition[P]) IsSatisfied(payload P) bool {
+	for _, conjunct := range c.conjuncts {
+		if !conjunct(payload) {
+			return false
+		}
+	}
+	return true
+}
+
+// Explain implements ExplainingCondition interface
+func (c *expressionCondition[P]) Explain(payload P) (bool, string) {
+	for i, conjunct := range c.conjuncts {
+		if !conjunct(payload) {
+			return false, describeResult(c.texts[i], false)
+		}
+	}
+	return true, describeResult(c.source, true)
+}
+
+// String returns the expression source
+func (c *expressionCondition[P]) String() string {
+	return c.source
+}
+
+// valueType is the type of an expression value
+type valueType int
+
+const (
+	boolValue valueType = iota
+	numberValue
+	stringValue
+)
+
+// String returns the name of the type used in error messages
+func (t valueType) String() string {
+	switch t {
+	case numberValue:
+		return "number"
+	case stringValue:
+		return "string"
+	default:
+		return "bool"
+	}
+}
+
+// compiledExpression evaluates an expression node, the function matching valueType is set
+type compiledExpression[P any] struct {
+	valueType valueType
+	boolean   func(payload P) bool
+	number    func(payload P) float64
+	text      func(payload P) string
+}
+
+// expressionNode is a node of a parsed expression
+type expressionNode struct {
+	kind     nodeKind
+	operator string      // operator of unary and binary nodes
+	literal  interface{} // bool, float64 or string value of literal nodes
+	path     []string    // field path of field nodes, starting with "payload"
+	operands []*expressionNode
+	start    int // byte offsets of the node in the source
+	end      int
+}
+
+// nodeKind distinguishes expression nodes
+type nodeKind int
+
+const (
+	literalNode nodeKind = iota
+	fieldNode
+	unaryNode
+	binaryNode
+)
+
+// text returns the source of the node
+func (n *expressionNode) text(source string) string {
+	return strings.TrimSpace(source[n.start:n.end])
+}
+
+// conjuncts returns the top-level operands of && of a node, or the node itself
+func conjuncts(node *expressionNode) []*expressionNode {
+	if node.kind == binaryNode && node.operator == "&&" {
+		return append(conjuncts(node.operands[0]), conjuncts(node.operands[1])...)
+	}
+	return []*expressionNode{node}
+}
+
+// expressionCompiler type-checks expression nodes against the payload type and turns them into functions
+type expressionCompiler[P any] struct {
+	source string
+	fields map[string]Field[P]
+}
+
+// errorf reports a compilation error at a node
+func (c *expressionCompiler[P]) errorf(node *expressionNode, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %q at offset %d: %s", ErrInvalidExpression, c.source, node.start, fmt.Sprintf(format, args...))
+}
+
+// compile compiles a node
+func (c *expressionCompiler[P]) compile(node *expressionNode) (compiledExpression[P], error) {
+	switch node.kind {
+	case literalNode:
+		switch literal := node.literal.(type) {
+		case bool:
+			return compiledExpression[P]{valueType: boolValue, boolean: func(P) bool { return literal }}, nil
+		case float64:
+			return compiledExpression[P]{valueType: numberValue, number: func(P) float64 { return literal }}, nil
+		default:
+			text := literal.(string)
+			return compiledExpression[P]{valueType: stringValue, text: func(P) string { return text }}, nil
+		}
+	case fieldNode:
+		return c.compileField(node)
+	case unaryNode:
+		operand, err := c.compile(node.operands[0])
+		if err != nil {
+			return operand, err
+		}
+		if operand.valueType != boolValue {
+			return operand, c.errorf(node, "operator ! needs a bool, got %s", operand.valueType)
+		}
+		return compiledExpression[P]{valueType: boolValue, boolean: func(payload P) bool {
+			return !operand.boolean(payload)
+		}}, nil
+	default:
+		return c.compileBinary(node)
+	}
+}
+
+// compileBinary compiles a logical operation or a comparison
+func (c *expressionCompiler[P]) compileBinary(node *expressionNode) (compiledExpression[P], error) {
+	left, err := c.compile(node.operands[0])
+	if err != nil {
+		return left, err
+	}
+	right, err := c.compile(node.operands[1])
+	if err != nil {
+		return right, err
+	}
+
+	result := compiledExpression[P]{valueType: boolValue}
+	switch node.operator {
+	case "&&", "||":
+		if left.valueType != boolValue || right.valueType != boolValue {
+			return result, c.errorf(node, "operator %s needs bool operands, got %s and %s", node.operator, left.valueType, right.valueType)
+		}
+		if node.operator == "&&" {
+			result.boolean = func(payload P) bool { return left.boolean(payload) && right.boolean(payload) }
+		} else {
+			result.boolean = func(payload P) bool { return left.boolean(payload) || right.boolean(payload) }
+		}
+		return result, nil
+	}
+
+	if left.valueType != right.valueType {
+		return result, c.errorf(node, "cannot compare %s with %s", left.valueType, right.valueType)
+	}
+	switch left.valueType {
+	case numberValue:
+		result.boolean = compareWith(node.operator, left.number, right.number)
+	case stringValue:
+		result.boolean = compareWith(node.operator, left.text, right.text)
+	default:
+		if node.operator != "==" && node.operator != "!=" {
+			return result, c.errorf(node, "operator %s is not defined on bool", node.operator)
+		}
+		result.boolean = compareWith(node.operator, func(payload P) int {
+			return boolRank(left.boolean(payload))
+		}, func(payload P) int {
+			return boolRank(right.boolean(payload))
+		})
+	}
+	return result, nil
+}
+
+// boolRank orders false before true so that bools can be compared like numbers
+func boolRank(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// compareWith builds a comparison of two ordered operands
+func compareWith[P any, T int | float64 | string](operator string, left, right func(payload P) T) func(payload P) bool {
+	switch operator {
+	case "==":
+		return func(payload P) bool { return left(payload) == right(payload) }
+	case "!=":
+		return func(payload P) bool { return left(payload) != right(payload) }
+	case "<":
+		return func(payload P) bool { return left(payload) < right(payload) }
+	case "<=":
+		return func(payload P) bool { return left(payload) <= right(payload) }
+	case ">":
+		return func(payload P) bool { return left(payload) > right(payload) }
+	default:
+		return func(payload P) bool { return left(payload) >= right(payload) }
+	}
+}
+
+// compileField compiles a field access, through a declared field or reflection
+func (c *expressionCompiler[P]) compileField(node *expressionNode) (compiledExpression[P], error) {
+	path := node.path[1:]
+	if field, ok := c.fields[strings.Join(path, ".")]; ok {
+		return field.compiled, nil
+	}
+
+	payloadType := reflect.TypeOf((*P)(nil)).Elem()
+	fieldType := payloadType
+	var steps []func(value reflect.Value) reflect.Value
+	for _, name := range path {
+		fieldType, steps = dereference(fieldType, steps)
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			field, ok := fieldType.FieldByName(name)
+			if !ok || !field.IsExported() {
+				return compiledExpression[P]{}, c.errorf(node, "%s has no exported field %s", fieldType, name)
+			}
+			index := field.Index
+			steps = append(steps, func(value reflect.Value) reflect.Value {
+				field, err := value.FieldByIndexErr(index)
+				if err != nil {
+					return reflect.Value{}
+				}
+				return field
+			})
+			fieldType = field.Type
+		case reflect.Map:
+			if fieldType.Key().Kind() != reflect.String {
+				return compiledExpression[P]{}, c.errorf(node, "%s is not keyed by strings", fieldType)
+			}
+			key := reflect.ValueOf(name).Convert(fieldType.Key())
+			steps = append(steps, func(value reflect.Value) reflect.Value {
+				return value.MapIndex(key)
+			})
+			fieldType = fieldType.Elem()
+		default:
+			return compiledExpression[P]{}, c.errorf(node, "cannot read field %s of %s", name, fieldType)
+		}
+	}
+	fieldType, steps = dereference(fieldType, steps)
+
+	read := func(payload P) reflect.Value {
+		value := reflect.ValueOf(&payload).Elem()
+		for _, step := range steps {
+			if !value.IsValid() {
+				break
+			}
+			value = step(value)
+		}
+		return value
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return compiledExpression[P]{valueType: boolValue, boolean: func(payload P) bool {
+			value := read(payload)
+			return value.IsValid() && value.Bool()
+		}}, nil
+	case reflect.String:
+		return compiledExpression[P]{valueType: stringValue, text: func(payload P) string {
+			if value := read(payload); value.IsValid() {
+				return value.String()
+			}
+			return ""
+		}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compiledExpression[P]{valueType: numberValue, number: func(payload P) float64 {
+			if value := read(payload); value.IsValid() {
+				return float64(value.Int())
+			}
+			return 0
+		}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compiledExpression[P]{valueType: numberValue, number: func(payload P) float64 {
+			if value := read(payload); value.IsValid() {
+				return float64(value.Uint())
+			}
+			return 0
+		}}, nil
+	case reflect.Float32, reflect.Float64:
+		return compiledExpression[P]{valueType: numberValue, number: func(payload P) float64 {
+			if value := read(payload); value.IsValid() {
+				return value.Float()
+			}
+			return 0
+		}}, nil
+	default:
+		return compiledExpression[P]{}, c.errorf(node, "%s has type %s, which expressions cannot compare", node.text(c.source), fieldType)
+	}
+}
+
+// dereference follows pointer types, adding the steps reading through the pointers
+// A nil pointer reads as an invalid value
+func dereference(valueType reflect.Type, steps []func(value reflect.Value) reflect.Value) (reflect.Type, []func(value reflect.Value) reflect.Value) {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+		steps = append(steps, func(value reflect.Value) reflect.Value {
+			if value.IsNil() {
+				return reflect.Value{}
+			}
+			return value.Elem()
+		})
+	}
+	return valueType, steps
+}
+
+// expressionToken is a lexical token of an expression
+type expressionToken struct {
+	kind  tokenKind
+	text  string
+	start int
+	end   int
+}
+
+// tokenKind distinguishes expression tokens
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	identToken
+	numberToken
+	stringToken
+	operatorToken
+)
+
+// expressionParser is a recursive descent parser of expressions
+type expressionParser struct {
+	source string
+	tokens []expressionToken
+	next   int
+}
+
+// parseExpression parses an expression without type-checking it
+func parseExpression(source string) (*expressionNode, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	parser := &expressionParser{source: source, tokens: tokens}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != endToken {
+		return nil, parser.errorf(token, "unexpected %q", token.text)
+	}
+	return node, nil
+}
+
+// errorf reports a syntax error at a token
+func (p *expressionParser) errorf(token expressionToken, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %q at offset %d: %s", ErrInvalidExpression, p.source, token.start, fmt.Sprintf(format, args...))
+}
+
+// peek returns the next token without consuming it
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token if it is the given operator
+func (p *expressionParser) accept(operators ...string) (expressionToken, bool) {
+	token := p.peek()
+	if token.kind == operatorToken {
+		for _, operator := range operators {
+			if token.text == operator {
+				p.next++
+				return token, true
+			}
+		}
+	}
+	return token, false
+}
+
+// parseOr parses operands of ||
+func (p *expressionParser) parseOr() (*expressionNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+// parseAnd parses operands of &&
+func (p *expressionParser) parseAnd() (*expressionNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+// parseBinary parses a left-associative chain of operands joined by one of the operators
+func (p *expressionParser) parseBinary(parseOperand func() (*expressionNode, error), operators ...string) (*expressionNode, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.accept(operators...)
+		if !ok {
+			return left, nil
+		}
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = &expressionNode{kind: binaryNode, operator: token.text, operands: []*expressionNode{left, right}, start: left.start, end: right.end}
+	}
+}
+
+// parseComparison parses an optional comparison of two unary operands
+func (p *expressionParser) parseComparison() (*expressionNode, error) {
+	return p.parseBinary(p.parseUnary, "==", "!=", "<", "<=", ">", ">=")
+}
+
+// parseUnary parses a negation or a primary expression
+func (p *expressionParser) parseUnary() (*expressionNode, error) {
+	if token, ok := p.accept("!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &expressionNode{kind: unaryNode, operator: "!", operands: []*expressionNode{operand}, start: token.start, end: operand.end}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a literal, a field access or a parenthesized expression
+func (p *expressionParser) parsePrimary() (*expressionNode, error) {
+	if open, ok := p.accept("("); ok {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.accept(")")
+		if !ok {
+			return nil, p.errorf(closing, "missing )")
+		}
+		node.start, node.end = open.start, closing.end
+		return node, nil
+	}
+
+	token := p.peek()
+	node := &expressionNode{kind: literalNode, start: token.start, end: token.end}
+	switch token.kind {
+	case numberToken:
+		number, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, p.errorf(token, "invalid number %s", token.text)
+		}
+		node.literal = number
+	case stringToken:
+		node.literal = token.text
+	case identToken:
+		switch {
+		case token.text == "true" || token.text == "false":
+			node.literal = token.text == "true"
+		case token.text == "payload" || strings.HasPrefix(token.text, "payload."):
+			node.kind, node.path = fieldNode, strings.Split(token.text, ".")
+		default:
+			return nil, p.errorf(token, "unknown name %s, fields are read as payload.Name", token.text)
+		}
+	case endToken:
+		return nil, p.errorf(token, "unexpected end of expression")
+	default:
+		return nil, p.errorf(token, "unexpected %q", token.text)
+	}
+	p.next++
+	return node, nil
+}
+
+// tokenize splits an expression into tokens, field paths such as payload.Customer.Tier form one token
+func tokenize(source string) ([]expressionToken, error) {
+	var tokens []expressionToken
+	syntaxError := func(offset int, format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %q at offset %d: %s", ErrInvalidExpression, source, offset, fmt.Sprintf(format, args...))
+	}
+
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(source) && rune(source[end]) != c {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, syntaxError(i, "unterminated string")
+			}
+			quoted := source[i : end+1]
+			if c == '\'' {
+				quoted = strconv.Quote(strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(source[i+1 : end]))
+			}
+			text, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, syntaxError(i, "invalid string %s", source[i:end+1])
+			}
+			tokens = append(tokens, expressionToken{kind: stringToken, text: text, start: i, end: end + 1})
+			i = end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1]))):
+			end := i + 1
+			for end < len(source) && (unicode.IsDigit(rune(source[end])) || source[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, expressionToken{kind: numberToken, text: source[i:end], start: i, end: end})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(source) && (unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end])) || source[end] == '_' || source[end] == '.') {
+				end++
+			}
+			text := source[i:end]
+			if strings.HasSuffix(text, ".") || strings.Contains(text, "..") {
+				return nil, syntaxError(i, "invalid field path %s", text)
+			}
+			tokens = append(tokens, expressionToken{kind: identToken, text: text, start: i, end: end})
+			i = end
+		default:
+			operator := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(source[i:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, syntaxError(i, "unexpected character %q", c)
+			}
+			tokens = append(tokens, expressionToken{kind: operatorToken, text: operator, start: i, end: i + len(operator)})
+			i += len(operator)
+		}
+	}
+	return append(tokens, expressionToken{kind: endToken, start: len(source), end: len(source)}), nil
+}
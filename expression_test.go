@@ -0,0 +1,172 @@
+package fsm
+
+import (
+	"errors"
+	"testing"
+)
+
+// expressionAddress is nested in expressionOrder to test paths through pointers
+type expressionAddress struct {
+	Country string
+}
+
+// expressionOrder is the payload compiled against in expression tests
+type expressionOrder struct {
+	Amount   int
+	Reviewer string
+	Rush     bool
+	Address  *expressionAddress
+	Tags     map[string]string
+	internal string
+}
+
+// TestCompileExpression tests the evaluation of expressions against struct payloads
+func TestCompileExpression(t *testing.T) {
+	order := &expressionOrder{
+		Amount:   150,
+		Reviewer: "alice",
+		Address:  &expressionAddress{Country: "NL"},
+		Tags:     map[string]string{"channel": "web"},
+	}
+
+	testCases := []struct {
+		source   string
+		expected bool
+	}{
+		{"payload.Amount > 100", true},
+		{"payload.Amount >= 150 && payload.Amount <= 150.0", true},
+		{"payload.Amount > 100 && payload.Reviewer == ''", false},
+		{`payload.Reviewer != "" || payload.Rush`, true},
+		{"!payload.Rush && !(payload.Amount < -5)", true},
+		{"payload.Address.Country == 'NL'", true},
+		{"payload.Tags.channel == 'web' && payload.Tags.missing == ''", true},
+		{"payload.Rush == false", true},
+		{"true", true},
+	}
+	for _, tc := range testCases {
+		condition, err := CompileExpression[*expressionOrder](tc.source)
+		if err != nil {
+			t.Errorf("Failed to compile %q: %v", tc.source, err)
+			continue
+		}
+		if satisfied := condition.IsSatisfied(order); satisfied != tc.expected {
+			t.Errorf("%q: expected %v, got %v", tc.source, tc.expected, satisfied)
+		}
+	}
+
+	condition, err := CompileExpression[*expressionOrder]("payload.Address.Country == ''")
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if !condition.IsSatisfied(&expressionOrder{}) || !condition.IsSatisfied(nil) {
+		t.Error("Expected nil pointers to read as zero values")
+	}
+}
+
+// TestCompileExpressionErrors tests that syntax and type errors are reported when compiling
+func TestCompileExpressionErrors(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"payload.Amount >",
+		"payload.Amount > 'ten'",
+		"payload.Reviewer < 3",
+		"payload.Amount",
+		"payload.Missing == 1",
+		"payload.internal == ''",
+		"payload.Address == nil",
+		"payload.Rush < true",
+		"(payload.Rush",
+		"amount > 1",
+		"payload.Reviewer == 'open",
+		"payload.Amount > 1 &",
+	} {
+		if _, err := CompileExpression[*expressionOrder](source); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%q: expected ErrInvalidExpression, got %v", source, err)
+		}
+	}
+}
+
+// TestExpressionFields tests that declared fields make expressions usable with interface payloads
+func TestExpressionFields(t *testing.T) {
+	options := ExpressionOptions[interface{}]{Fields: map[string]Field[interface{}]{
+		"value": StringField(func(payload interface{}) string {
+			value, _ := payload.(string)
+			return value
+		}),
+		"length": NumberField(func(payload interface{}) float64 {
+			value, _ := payload.(string)
+			return float64(len(value))
+		}),
+	}}
+
+	condition, err := CompileExpression("payload.value != 'stop' && payload.length < 5", options)
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if !condition.IsSatisfied("go") || condition.IsSatisfied("stop") || condition.IsSatisfied("onward") {
+		t.Error("Unexpected evaluation of declared fields")
+	}
+
+	if _, err := CompileExpression("payload.other == 1", options); !errors.Is(err, ErrInvalidExpression) {
+		t.Errorf("Expected ErrInvalidExpression for an undeclared field of an interface payload, got %v", err)
+	}
+}
+
+// TestExpressionExplain tests that explanations name the first conjunct that is not satisfied
+func TestExpressionExplain(t *testing.T) {
+	condition, err := CompileExpression[*expressionOrder]("payload.Amount > 0 && payload.Reviewer != ''")
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	if description := DescribeCondition(condition); description != "payload.Amount > 0 && payload.Reviewer != ''" {
+		t.Errorf("Unexpected description %q", description)
+	}
+	satisfied, reason := condition.(ExplainingCondition[*expressionOrder]).Explain(&expressionOrder{Amount: 5})
+	if satisfied || reason != "payload.Reviewer != '' not satisfied" {
+		t.Errorf("Unexpected explanation %v %q", satisfied, reason)
+	}
+}
+
+// TestImportDefinitionGuard tests that guard expressions of a definition are compiled and combined with named conditions
+func TestImportDefinitionGuard(t *testing.T) {
+	definition, err := ParseDefinition([]byte(`{
+		"id": "ExpressionGuardMachine",
+		"states": [{"name": "Draft"}, {"name": "Review"}],
+		"events": [{"name": "Submit"}],
+		"transitions": [
+			{"from": ["Draft"], "to": ["Review"], "on": "Submit", "when": "reviewed", "guard": "payload.Amount > 100"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse definition: %v", err)
+	}
+
+	registry := NewRegistry[string, string, *expressionOrder]()
+	registry.RegisterCondition("reviewed", ConditionFunc[*expressionOrder](func(payload *expressionOrder) bool {
+		return payload.Reviewer != ""
+	}))
+	builder, err := ImportDefinition(definition, ImportOptions[string, string, *expressionOrder]{Registry: registry})
+	if err != nil {
+		t.Fatalf("Failed to import definition: %v", err)
+	}
+	machine, err := builder.Build("ExpressionGuardMachine")
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+
+	if _, err := machine.FireEvent("Draft", "Submit", &expressionOrder{Amount: 50, Reviewer: "alice"}); err == nil {
+		t.Error("Expected the guard expression to block the transition")
+	}
+	if _, err := machine.FireEvent("Draft", "Submit", &expressionOrder{Amount: 500}); err == nil {
+		t.Error("Expected the named condition to block the transition")
+	}
+	if state, err := machine.FireEvent("Draft", "Submit", &expressionOrder{Amount: 500, Reviewer: "alice"}); err != nil || state != "Review" {
+		t.Errorf("Expected Review, got %v %v", state, err)
+	}
+
+	definition.Transitions[0].Guard = "payload.Amount >"
+	if err := definition.Validate(); !errors.Is(err, ErrInvalidDefinition) {
+		t.Errorf("Expected a syntax error to invalidate the definition, got %v", err)
+	}
+}
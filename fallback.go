@@ -0,0 +1,23 @@
+package fsm
+
+// ErrorRoute sends the machine to a fallback state when the action of a transition fails
+type ErrorRoute[S comparable, E comparable, P any] struct {
+	// Match selects the failures handled by the route, nil handles every failure
+	// It receives the error returned by the action, a *PanicError or ErrActionTimeout
+	Match func(err error) bool
+	// Transition leads from the source state of the failed transition to the fallback state
+	Transition *Transition[S, E, P]
+}
+
+// errorRoute returns the first error route matching the failure of the transition's action, nil if none does
+func (t *Transition[S, E, P]) errorRoute(err error) *ErrorRoute[S, E, P] {
+	if err == nil {
+		return nil
+	}
+	for i := range t.ErrorRoutes {
+		if route := &t.ErrorRoutes[i]; route.Match == nil || route.Match(err) {
+			return route
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var errCardDeclined = errors.New("card declined")
+
+// createFallbackStateMachine creates a machine paying from A to B that falls back to C on declined cards
+// and to D on any other failure
+func createFallbackStateMachine(t *testing.T, id string) StateMachine[testState, testEvent, testPayload] {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			switch payload.Value {
+			case "declined":
+				return fmt.Errorf("charge: %w", errCardDeclined)
+			case "offline":
+				return errors.New("gateway offline")
+			}
+			return nil
+		}).
+		OnError(StateC, func(err error) bool {
+			return errors.Is(err, errCardDeclined)
+		}).
+		OnError(StateD)
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return errors.New("boom")
+		}).
+		OnError(StateD, func(err error) bool {
+			return false
+		})
+
+	sm, err := builder.Build(id)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	return sm
+}
+
+// TestOnError tests that failing actions are routed to the first matching fallback state
+func TestOnError(t *testing.T) {
+	sm := createFallbackStateMachine(t, "OnErrorTest")
+
+	testCases := []struct {
+		value    string
+		expected testState
+	}{
+		{"", StateB},
+		{"declined", StateC},
+		{"offline", StateD},
+	}
+	for _, tc := range testCases {
+		target, err := sm.FireEvent(StateA, Event1, testPayload{Value: tc.value})
+		if err != nil || target != tc.expected {
+			t.Errorf("Payload %q: expected %v, got %v: %v", tc.value, tc.expected, target, err)
+		}
+	}
+
+	if _, err := sm.FireEvent(StateB, Event2, testPayload{}); !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected the failure to be returned when no route matches, got %v", err)
+	}
+}
+
+// TestOnErrorObservers tests that listeners and results see the failure and the fallback transition
+func TestOnErrorObservers(t *testing.T) {
+	sm := createFallbackStateMachine(t, "OnErrorObserversTest")
+
+	var calls []string
+	sm.AddListener(ListenerFuncs[testState, testEvent, testPayload]{
+		After: func(from, to testState, event testEvent, payload testPayload) {
+			calls = append(calls, fmt.Sprintf("after %v->%v", from, to))
+		},
+		Error: func(from, to testState, event testEvent, payload testPayload, err error) {
+			calls = append(calls, fmt.Sprintf("error %v->%v", from, to))
+		},
+	})
+
+	result, err := sm.Fire(StateA, Event1, testPayload{Value: "declined"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"error A->B", "after A->C"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected listener calls %v, got %v", expected, calls)
+	}
+	if result.Target != StateC || len(result.Transitions) != 1 || result.Transitions[0].Target != StateC {
+		t.Errorf("Expected the result to report the fallback transition, got %+v", result)
+	}
+}
@@ -1,9 +1,11 @@
 package fsm
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // StateMachine is a generic state machine interface
@@ -15,14 +17,62 @@ type StateMachine[S comparable, E comparable, P any] interface {
 	// Returns the new state and any error that occurred
 	FireEvent(sourceState S, event E, payload P) (S, error)
 
+	// FireEventContext is like FireEvent but passes the context through the middleware chain
+	FireEventContext(ctx context.Context, sourceState S, event E, payload P) (S, error)
+
 	// FireParallelEvent triggers parallel state transitions based on the current state and event
 	// Returns a slice of new states and any error that occurred
+	// When a branch fails, the branches completed before it are compensated, the states of those
+	// without a compensation are returned along with the error
 	FireParallelEvent(sourceState S, event E, payload P) ([]S, error)
 
+	// Fire triggers a state transition like FireEvent and reports the executed transition,
+	// the number of evaluated conditions and the action duration
+	Fire(sourceState S, event E, payload P) (Result[S, E], error)
+
+	// FireParallel triggers parallel state transitions like FireParallelEvent and reports the details of each branch
+	FireParallel(sourceState S, event E, payload P) (Result[S, E], error)
+
 	// Verify checks if there is a valid transition for the given state and event
+	// Conditions are not evaluated, use Simulate to take the payload into account
 	// Returns true if a transition exists, false otherwise
 	Verify(sourceState S, event E) bool
 
+	// Simulate evaluates conditions and reports the transition(s) an event would take without executing actions
+	// Returns the simulation result and the error FireEvent would return, if any
+	Simulate(sourceState S, event E, payload P) (SimulationResult[S, E], error)
+
+	// Explain evaluates every candidate transition for an event and reports each condition's result
+	// and reason, and which transition FireEvent would take; actions are not executed
+	Explain(sourceState S, event E, payload P) Explanation[S, E]
+
+	// AvailableEvents returns the events that have at least one transition from the given state
+	// Events are returned in the order their first transition was declared, whatever the priorities of the transitions;
+	// conditions are not evaluated
+	AvailableEvents(state S) []E
+
+	// AvailableEventsFor returns the events that have at least one transition from the given state
+	// whose condition is satisfied by the payload, in the same order as AvailableEvents; actions are not executed
+	AvailableEventsFor(state S, payload P) []E
+
+	// Transitions returns read-only descriptors of all transitions leaving the given state
+	// They are grouped by event in the order of AvailableEvents, the transitions of an event in evaluation order:
+	// by descending priority, then in declaration order
+	Transitions(state S) []TransitionInfo[S, E]
+
+	// States returns all states of the state machine in declaration order
+	States() []S
+
+	// Events returns all events used by the state machine in order of first appearance
+	Events() []E
+
+	// AddListener registers a listener that observes the transitions of this state machine
+	AddListener(listener Listener[S, E, P])
+
+	// Use appends middleware around FireEvent and FireEventContext
+	// The first middleware registered is the outermost one
+	Use(middleware ...Middleware[S, E, P])
+
 	// ShowStateMachine returns a string representation of the state machine
 	ShowStateMachine() string
 
@@ -44,12 +94,17 @@ const (
 	MarkdownFlowchart
 	// MarkdownStateDiagram format for Mermaid state diagrams
 	MarkdownStateDiagram
+	// Graphviz format for DOT graphs
+	Graphviz
 )
 
 // State represents a state in the state machine
 type State[S comparable, E comparable, P any] struct {
 	id               S
 	eventTransitions map[E][]*Transition[S, E, P]
+	events           []E        // events in declaration order
+	ignored          map[E]bool // events without transitions that leave the machine in this state, see Ignore
+	declared         int        // transitions added so far, numbering them in declaration order
 }
 
 // NewState creates a new state
@@ -67,12 +122,17 @@ func (s *State[S, E, P]) AddTransition(event E, target *State[S, E, P], transTyp
 		Target:    target,
 		Event:     event,
 		TransType: transType,
+		sequence:  s.declared,
 	}
+	s.declared++
 
 	if _, ok := s.eventTransitions[event]; !ok {
 		s.eventTransitions[event] = make([]*Transition[S, E, P], 0)
+		s.events = append(s.events, event)
 	}
 	s.eventTransitions[event] = append(s.eventTransitions[event], transition)
+	// Keep the transitions in evaluation order, after those of equal or higher priority
+	s.sortByPriority(event)
 	return transition
 }
 
@@ -108,6 +168,14 @@ const (
 	Internal
 )
 
+// String returns the name of the transition type
+func (t TransitionType) String() string {
+	if t == Internal {
+		return "Internal"
+	}
+	return "External"
+}
+
 // Condition is an interface for transition conditions
 type Condition[P any] interface {
 	// IsSatisfied returns true if the condition is met
@@ -122,6 +190,28 @@ func (f ConditionFunc[P]) IsSatisfied(payload P) bool {
 	return f(payload)
 }
 
+// ExplainingCondition is a condition that can also tell why it is or is not satisfied
+type ExplainingCondition[P any] interface {
+	Condition[P]
+
+	// Explain returns whether the condition is met and a human-readable reason
+	Explain(payload P) (bool, string)
+}
+
+// ExplainingConditionFunc is a function type that implements ExplainingCondition interface
+type ExplainingConditionFunc[P any] func(payload P) (bool, string)
+
+// IsSatisfied implements Condition interface
+func (f ExplainingConditionFunc[P]) IsSatisfied(payload P) bool {
+	satisfied, _ := f(payload)
+	return satisfied
+}
+
+// Explain implements ExplainingCondition interface
+func (f ExplainingConditionFunc[P]) Explain(payload P) (bool, string) {
+	return f(payload)
+}
+
 // Action is an interface for transition actions
 type Action[S comparable, E comparable, P any] interface {
 	// Execute runs the action during a state transition
@@ -138,12 +228,77 @@ func (f ActionFunc[S, E, P]) Execute(from, to S, event E, payload P) error {
 
 // Transition represents a state transition
 type Transition[S comparable, E comparable, P any] struct {
-	Source    *State[S, E, P]
-	Target    *State[S, E, P]
-	Event     E
-	Condition Condition[P]
-	Action    Action[S, E, P]
-	TransType TransitionType
+	Source       *State[S, E, P]
+	Target       *State[S, E, P]
+	Event        E
+	Condition    Condition[P]
+	Action       Action[S, E, P]
+	TransType    TransitionType
+	Compensation Action[S, E, P]       // undoes the action when a later branch of the same firing fails
+	Retry        *RetryPolicy          // retries the action when it fails, nil runs it once
+	Timeout      time.Duration         // limit of each action attempt, zero uses the machine default
+	ErrorRoutes  []ErrorRoute[S, E, P] // fallback states taken when the action fails, the first matching route wins
+	Priority     int                   // higher priorities are evaluated first, equal priorities in declaration order
+	sequence     int                   // position in the declaration order of the source state's transitions
+	parallel     bool                  // branch of a parallel transition, which never makes a firing ambiguous
+	Name         string
+	Metadata     map[string]string
+}
+
+// TransitionInfo is a read-only description of a transition
+type TransitionInfo[S comparable, E comparable] struct {
+	Source       S
+	Target       S
+	Event        E
+	Type         TransitionType
+	HasCondition bool
+	HasAction    bool
+	Condition    string // description of the condition, see DescribeCondition
+	Priority     int
+	Name         string
+	Metadata     map[string]string // a copy, changing it does not affect the transition
+}
+
+// Info returns a read-only description of the transition
+func (t *Transition[S, E, P]) Info() TransitionInfo[S, E] {
+	var condition string
+	if t.Condition != nil {
+		condition = DescribeCondition(t.Condition)
+	}
+	return TransitionInfo[S, E]{
+		Source:       t.Source.GetID(),
+		Target:       t.Target.GetID(),
+		Event:        t.Event,
+		Type:         t.TransType,
+		HasCondition: t.Condition != nil,
+		HasAction:    t.Action != nil,
+		Condition:    condition,
+		Priority:     t.Priority,
+		Name:         t.Name,
+		Metadata:     copyMetadata(t.Metadata),
+	}
+}
+
+// copyMetadata returns a copy of the metadata of a transition, nil without metadata
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
+
+// guardLabel returns the description of the condition in brackets for diagrams
+// It is empty unless every operand of the condition has a name, so that labels never show placeholders
+// and ImportDiagram can resolve them
+func (t *Transition[S, E, P]) guardLabel() string {
+	if t.Condition == nil || !isLabelled(t.Condition) {
+		return ""
+	}
+	return " [" + DescribeCondition(t.Condition) + "]"
 }
 
 // Transit executes the transition
@@ -170,10 +325,26 @@ func (t *Transition[S, E, P]) Transit(payload P, checkCondition bool) (*State[S,
 
 // StateMachineImpl implements the StateMachine interface
 type StateMachineImpl[S comparable, E comparable, P any] struct {
-	id       string
-	stateMap map[S]*State[S, E, P]
-	ready    bool
-	mutex    sync.RWMutex
+	id              string
+	stateMap        map[S]*State[S, E, P]
+	stateOrder      []S // states in declaration order
+	listeners       []Listener[S, E, P]
+	middleware      []Middleware[S, E, P]
+	fireChain       FireFunc[S, E, P] // middleware composed around fireEvent, nil without middleware
+	metrics         Metrics
+	tracer          Tracer
+	logger          Logger
+	logLevels       LevelPolicy
+	recoverPanics   bool // convert panics in conditions and actions into *PanicError
+	panicHandler    PanicHandler
+	clock           Clock
+	parallelOptions *ParallelOptions               // concurrent execution of parallel branches, nil runs them in order
+	defaultTimeout  time.Duration                  // action timeout of transitions without their own, zero means none
+	strict          bool                           // reject single firings where candidates of the same priority are satisfied
+	unhandledEvents UnhandledEventPolicy           // what firing does with events no transition is declared for
+	onUnhandled     UnhandledEventHandler[S, E, P] // receives unhandled events under UnhandledEventFallback
+	ready           bool
+	mutex           sync.RWMutex
 }
 
 // newStateMachine creates a new state machine (package private)
@@ -187,90 +358,282 @@ func newStateMachine[S comparable, E comparable, P any](id string) *StateMachine
 
 // FireEvent triggers a state transition based on the current state and event
 func (sm *StateMachineImpl[S, E, P]) FireEvent(sourceStateId S, event E, payload P) (S, error) {
+	return sm.FireEventContext(context.Background(), sourceStateId, event, payload)
+}
+
+// FireEventContext triggers a state transition, passing the context through the middleware chain
+func (sm *StateMachineImpl[S, E, P]) FireEventContext(ctx context.Context, sourceStateId S, event E, payload P) (S, error) {
+	sm.mutex.RLock()
+	fire := sm.fireChain
+	sm.mutex.RUnlock()
+
+	if fire == nil {
+		return sm.fireEvent(ctx, sourceStateId, event, payload, nil)
+	}
+	return fire(ctx, sourceStateId, event, payload)
+}
+
+// fireEvent is the core of FireEvent, wrapped by the middleware chain
+// result is filled with the details of the firing unless it is nil
+func (sm *StateMachineImpl[S, E, P]) fireEvent(ctx context.Context, sourceStateId S, event E, payload P, result *Result[S, E]) (S, error) {
+	targets, err := sm.fire(ctx, sourceStateId, event, payload, false, result)
+	if err != nil {
+		var zeroState S
+		return zeroState, err
+	}
+	return targets[0], nil
+}
+
+// FireParallelEvent triggers parallel state transitions based on the current state and event
+// A failed firing does not return nil states: the error comes with the states of the branches that completed
+// and were not compensated, in sequential and concurrent execution alike, so callers see which effects remain.
+// The states are empty when no branch completed or every completed branch was compensated
+func (sm *StateMachineImpl[S, E, P]) FireParallelEvent(sourceStateId S, event E, payload P) ([]S, error) {
+	return sm.fire(context.Background(), sourceStateId, event, payload, true, nil)
+}
+
+// fire selects and executes the transitions for an event
+// Candidates are evaluated by priority. A single firing takes the first transition whose condition is satisfied,
+// or fails with ErrAmbiguousTransition in strict mode when another one of the same priority is satisfied too;
+// a parallel firing takes all of them. result is filled with the details of the firing unless it is nil
+func (sm *StateMachineImpl[S, E, P]) fire(ctx context.Context, sourceStateId S, event E, payload P, parallel bool, result *Result[S, E]) (targets []S, err error) {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
 	if !sm.ready {
-		var zeroState S
-		return zeroState, ErrStateMachineNotReady
+		return nil, ErrStateMachineNotReady
+	}
+
+	// Transitions that were executed, or the one that failed, reported to the logger
+	var taken []*Transition[S, E, P]
+
+	if sm.metrics != nil || sm.logger != nil {
+		start := time.Now()
+		defer func() {
+			duration := time.Since(start)
+			if sm.metrics != nil {
+				sm.observeFiring(sourceStateId, event, targets, err, duration)
+			}
+			if sm.logger != nil {
+				sm.logFiring(ctx, sourceStateId, event, taken, err, duration)
+			}
+		}()
+	}
+
+	if sm.tracer != nil {
+		spanName := SpanFireEvent
+		if parallel {
+			spanName = SpanFireParallelEvent
+		}
+		var span Span
+		ctx, span = sm.startSpan(ctx, spanName, sourceStateId, event)
+		defer func() {
+			endFireSpan(span, targets, err)
+		}()
+	}
+
+	listeners := sm.activeListeners()
+	f := &firing[S, E]{ctx: ctx, parallel: parallel, result: result}
+	if f.result != nil {
+		defer func() {
+			if f.scope != nil {
+				f.scope.mutex.Lock()
+				f.result.Raised = append([]E(nil), f.scope.raised...)
+				f.scope.mutex.Unlock()
+			}
+		}()
 	}
 
 	// Get source state
 	sourceState, ok := sm.stateMap[sourceStateId]
 	if !ok {
-		var zeroState S
-		return zeroState, ErrStateNotFound
+		return nil, sm.decline(listeners, sourceStateId, event, payload, ErrStateNotFound)
 	}
 
 	// Get transitions for the event
 	transitions := sourceState.GetEventTransitions(event)
-	if transitions == nil || len(transitions) == 0 {
-		var zeroState S
-		return zeroState, ErrTransitionNotFound
+	if len(transitions) == 0 {
+		return sm.unhandled(f, listeners, sourceState, event, payload)
 	}
 
-	// Find the first transition with satisfied condition
+	// Find the transitions with satisfied conditions
+	var selected []*Transition[S, E, P]
 	for _, transition := range transitions {
-		if transition.Condition == nil || transition.Condition.IsSatisfied(payload) {
-			targetState, err := transition.Transit(payload, true)
-			if err != nil {
-				var zeroState S
-				return zeroState, err
+		if !parallel && len(selected) > 0 && !sm.contends(selected[0], transition) {
+			break
+		}
+		if f.result != nil && transition.Condition != nil {
+			f.result.GuardEvaluations++
+		}
+		satisfied, err := sm.checkCondition(f, transition, payload)
+		if err != nil {
+			return nil, err
+		}
+		if satisfied {
+			if !parallel && len(selected) > 0 {
+				return nil, sm.decline(listeners, sourceStateId, event, payload, ambiguityError(selected[0], transition))
 			}
-			return targetState.GetID(), nil
+			selected = append(selected, transition)
 		}
 	}
 
-	var zeroState S
-	return zeroState, ErrConditionNotMet
-}
-
-// FireParallelEvent triggers parallel state transitions based on the current state and event
-func (sm *StateMachineImpl[S, E, P]) FireParallelEvent(sourceStateId S, event E, payload P) ([]S, error) {
-	sm.mutex.RLock()
-	defer sm.mutex.RUnlock()
+	if len(selected) == 0 {
+		return nil, sm.decline(listeners, sourceStateId, event, payload, ErrConditionNotMet)
+	}
 
-	if !sm.ready {
-		return nil, ErrStateMachineNotReady
+	if parallel && sm.parallelOptions != nil && len(selected) > 1 {
+		targets, taken, err = sm.transitConcurrently(f, listeners, selected, payload)
+		return targets, err
 	}
 
-	// Get source state
-	sourceState, ok := sm.stateMap[sourceStateId]
-	if !ok {
-		return nil, ErrStateNotFound
+	// Then execute them in evaluation order
+	// A failing parallel branch stops the firing, the branches completed before it are compensated
+	// and those without a compensation are returned with the error
+	targets = make([]S, 0, len(selected))
+	for _, transition := range selected {
+		executed, duration, err := sm.transit(f, listeners, transition, payload)
+		if err != nil {
+			completed := taken
+			taken = []*Transition[S, E, P]{transition}
+			return sm.compensate(f, completed, payload, err)
+		}
+		taken = append(taken, executed)
+		targets = append(targets, executed.Target.GetID())
+		if f.result != nil {
+			f.result.record(executed.Info(), duration)
+		}
 	}
 
-	// Get transitions for the event
-	transitions := sourceState.GetEventTransitions(event)
-	if transitions == nil || len(transitions) == 0 {
-		return nil, ErrTransitionNotFound
+	return targets, nil
+}
+
+// decline notifies listeners that an event was not handled and returns the reason
+func (sm *StateMachineImpl[S, E, P]) decline(listeners []Listener[S, E, P], sourceStateId S, event E, payload P, reason error) error {
+	for _, listener := range listeners {
+		listener.OnTransitionDeclined(sourceStateId, event, payload, reason)
 	}
+	return reason
+}
 
-	// Execute all transitions with satisfied conditions
-	var results []S
-	var validTransitions []*Transition[S, E, P]
+// transit executes a selected transition, notifying listeners around the action
+// It returns the transition that was taken, which is the fallback transition of an error route
+// when the action failed and a route of the transition matched the failure
+// The action duration is measured when a result is requested or metrics are collected
+func (sm *StateMachineImpl[S, E, P]) transit(f *firing[S, E], listeners []Listener[S, E, P], transition *Transition[S, E, P], payload P) (*Transition[S, E, P], time.Duration, error) {
+	from, to := transition.Source.GetID(), transition.Target.GetID()
 
-	// First, find all valid transitions
-	for _, transition := range transitions {
-		if transition.Condition == nil || transition.Condition.IsSatisfied(payload) {
-			validTransitions = append(validTransitions, transition)
+	for _, listener := range listeners {
+		if err := listener.BeforeTransition(from, to, transition.Event, payload); err != nil {
+			return nil, 0, sm.decline(listeners, from, transition.Event, payload, err)
 		}
 	}
 
-	if len(validTransitions) == 0 {
-		return nil, ErrConditionNotMet
+	var span Span
+	action := f
+	if transition.Action != nil {
+		var ctx context.Context
+		ctx, span = sm.startSpan(f.ctx, SpanAction, from, transition.Event, Attr(AttributeTarget, fmt.Sprint(to)))
+		if span != nil {
+			// Contextual actions run in the context of the action span, so the spans they start are its children
+			if f.scope == nil {
+				f.scope = &firingScope[E]{}
+			}
+			action = &firing[S, E]{ctx: ctx, parallel: f.parallel, result: f.result, scope: f.scope}
+		}
+	}
+	timed := (f.result != nil || sm.metrics != nil) && transition.Action != nil
+	var start time.Time
+	if timed {
+		start = time.Now()
+	}
+	var err, cause error
+	var attempts int
+	var run *actionRun
+	if transition.TransType == Internal && transition.Source != transition.Target {
+		err = ErrInternalTransition
+	} else if attempts, run, cause = sm.executeWithRetry(action, transition, payload); cause != nil {
+		err = sm.actionError(action, transition, cause)
+	}
+	var duration time.Duration
+	if timed {
+		duration = time.Since(start)
+	}
+	if sm.metrics != nil && transition.Action != nil {
+		sm.metrics.ObserveAction(sm.id, fmt.Sprint(from), fmt.Sprint(transition.Event), fmt.Sprint(to), duration, err)
+	}
+	if span != nil {
+		if attempts > 1 {
+			span.SetAttributes(Attr(AttributeAttempts, attempts))
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+	if err != nil {
+		for _, listener := range listeners {
+			listener.OnTransitionError(from, to, transition.Event, payload, err)
+		}
+		route := transition.errorRoute(cause)
+		if route == nil {
+			// The actions of a chain that succeeded before the failure are undone by the compensation
+			if run != nil && run.partial() && transition.Compensation != nil {
+				_, err = sm.compensate(f, []*Transition[S, E, P]{transition}, payload, err)
+			}
+			return nil, duration, err
+		}
+		transition, to = route.Transition, route.Transition.Target.GetID()
+	}
+
+	for _, listener := range listeners {
+		listener.AfterTransition(from, to, transition.Event, payload)
+	}
+	return transition, duration, nil
+}
+
+// checkCondition evaluates the condition of a transition while firing
+// The error is a *PanicError when the condition panicked and panic recovery is enabled
+func (sm *StateMachineImpl[S, E, P]) checkCondition(f *firing[S, E], transition *Transition[S, E, P], payload P) (satisfied bool, err error) {
+	if transition.Condition == nil {
+		return true, nil
 	}
 
-	// Then execute all valid transitions
-	for _, transition := range validTransitions {
-		targetState, err := transition.Transit(payload, false) // Skip condition check as we've already verified it
+	var span Span
+	if sm.tracer != nil {
+		attributes := []Attribute{Attr(AttributeTarget, fmt.Sprint(transition.Target.GetID()))}
+		if description := DescribeCondition(transition.Condition); description != "" {
+			attributes = append(attributes, Attr(AttributeCondition, description))
+		}
+		_, span = sm.startSpan(f.ctx, SpanCondition, transition.Source.GetID(), transition.Event, attributes...)
+	}
+	var start time.Time
+	if sm.metrics != nil {
+		start = time.Now()
+	}
+	err = sm.protect(PhaseCondition, transition, func() {
+		satisfied = sm.isSatisfied(f, transition, payload)
+	})
+	if sm.metrics != nil {
+		sm.metrics.ObserveCondition(sm.id, fmt.Sprint(transition.Source.GetID()), fmt.Sprint(transition.Event), time.Since(start))
+	}
+	if span != nil {
 		if err != nil {
-			return nil, err
+			span.RecordError(err)
+		} else {
+			span.SetAttributes(Attr(AttributeSatisfied, satisfied))
 		}
-		results = append(results, targetState.GetID())
+		span.End()
 	}
+	return satisfied, err
+}
 
-	return results, nil
+// observeFiring reports a completed firing to the metrics collector
+func (sm *StateMachineImpl[S, E, P]) observeFiring(sourceStateId S, event E, targets []S, err error, duration time.Duration) {
+	labels := make([]string, len(targets))
+	for i, target := range targets {
+		labels[i] = fmt.Sprint(target)
+	}
+	sm.metrics.ObserveFiring(sm.id, fmt.Sprint(sourceStateId), fmt.Sprint(event), labels, outcomeOf(err), duration)
 }
 
 // Verify checks if there is a valid transition for the given state and event
@@ -295,6 +658,96 @@ func (sm *StateMachineImpl[S, E, P]) Verify(sourceStateId S, event E) bool {
 	return transitions != nil && len(transitions) > 0
 }
 
+// AvailableEvents returns the events that have at least one transition from the given state
+// in the order their first transition was declared
+func (sm *StateMachineImpl[S, E, P]) AvailableEvents(stateId S) []E {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	state, ok := sm.stateMap[stateId]
+	if !sm.ready || !ok {
+		return nil
+	}
+
+	return append([]E(nil), state.events...)
+}
+
+// AvailableEventsFor returns the events from the given state with at least one satisfied condition
+func (sm *StateMachineImpl[S, E, P]) AvailableEventsFor(stateId S, payload P) []E {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	state, ok := sm.stateMap[stateId]
+	if !sm.ready || !ok {
+		return nil
+	}
+
+	var events []E
+	for _, event := range state.events {
+		for _, transition := range state.eventTransitions[event] {
+			if transition.Condition == nil || sm.isSatisfied(nil, transition, payload) {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// Transitions returns read-only descriptors of all transitions leaving the given state
+// grouped by event, the transitions of an event by descending priority and then in declaration order
+func (sm *StateMachineImpl[S, E, P]) Transitions(stateId S) []TransitionInfo[S, E] {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	state, ok := sm.stateMap[stateId]
+	if !sm.ready || !ok {
+		return nil
+	}
+
+	var result []TransitionInfo[S, E]
+	for _, event := range state.events {
+		for _, transition := range state.eventTransitions[event] {
+			result = append(result, transition.Info())
+		}
+	}
+	return result
+}
+
+// States returns all states of the state machine in declaration order
+func (sm *StateMachineImpl[S, E, P]) States() []S {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if !sm.ready {
+		return nil
+	}
+
+	return append([]S(nil), sm.stateOrder...)
+}
+
+// Events returns all events used by the state machine in order of first appearance
+func (sm *StateMachineImpl[S, E, P]) Events() []E {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	if !sm.ready {
+		return nil
+	}
+
+	var events []E
+	seen := make(map[E]bool)
+	for _, stateId := range sm.stateOrder {
+		for _, event := range sm.stateMap[stateId].events {
+			if !seen[event] {
+				seen[event] = true
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
 // GetState returns a state by ID, creating it if it doesn't exist
 func (sm *StateMachineImpl[S, E, P]) GetState(stateId S) *State[S, E, P] {
 	sm.mutex.Lock()
@@ -306,6 +759,7 @@ func (sm *StateMachineImpl[S, E, P]) GetState(stateId S) *State[S, E, P] {
 
 	state := NewState[S, E, P](stateId)
 	sm.stateMap[stateId] = state
+	sm.stateOrder = append(sm.stateOrder, stateId)
 	return state
 }
 
@@ -323,22 +777,32 @@ func (sm *StateMachineImpl[S, E, P]) ShowStateMachine() string {
 
 	result := fmt.Sprintf("StateMachine(id=%s):\n", sm.id)
 
-	for _, state := range sm.stateMap {
-		for event, transitions := range state.eventTransitions {
-			for _, transition := range transitions {
-				transType := "EXTERNAL"
-				if transition.TransType == Internal {
-					transType = "INTERNAL"
-				}
-				result += fmt.Sprintf("  %v --%v(%s)--> %v\n",
-					transition.Source.GetID(), event, transType, transition.Target.GetID())
-			}
+	for _, transition := range sm.transitionsInOrder() {
+		transType := "EXTERNAL"
+		if transition.TransType == Internal {
+			transType = "INTERNAL"
 		}
+		result += fmt.Sprintf("  %v --%v(%s)--> %v%s\n",
+			transition.Source.GetID(), transition.Event, transType, transition.Target.GetID(), transition.guardLabel())
 	}
 
 	return result
 }
 
+// transitionsInOrder returns all transitions grouped by source state and event, states and events in declaration order
+// and the transitions of an event in evaluation order
+// The caller must hold the mutex
+func (sm *StateMachineImpl[S, E, P]) transitionsInOrder() []*Transition[S, E, P] {
+	var result []*Transition[S, E, P]
+	for _, stateId := range sm.stateOrder {
+		state := sm.stateMap[stateId]
+		for _, event := range state.events {
+			result = append(result, state.eventTransitions[event]...)
+		}
+	}
+	return result
+}
+
 // GenerateDiagram returns a diagram of the state machine in the specified formats
 // If formats is nil or empty, defaults to PlantUML
 // If multiple formats are provided, returns all requested formats concatenated
@@ -360,6 +824,8 @@ func (sm *StateMachineImpl[S, E, P]) GenerateDiagram(formats ...DiagramFormat) s
 			result.WriteString(sm.generateMarkdownFlow())
 		case MarkdownStateDiagram:
 			result.WriteString(sm.generateMarkdownStateDiagram())
+		case Graphviz:
+			result.WriteString(sm.generateGraphviz())
 		case PlantUML:
 			result.WriteString(sm.generatePlantUML())
 		default:
@@ -380,16 +846,16 @@ func (sm *StateMachineImpl[S, E, P]) generatePlantUML() string {
 	sb.WriteString(fmt.Sprintf("title StateMachine: %s\n", sm.id))
 
 	// Define states
-	for stateId := range sm.stateMap {
+	for _, stateId := range sm.stateOrder {
 		sb.WriteString(fmt.Sprintf("state \"%v\" as %v\n", stateId, stateId))
 	}
 
 	// Define transitions
-	for _, state := range sm.stateMap {
-		for _, transitions := range state.eventTransitions {
-			for _, transition := range transitions {
-				sb.WriteString(fmt.Sprintf("%v --> %v : %v\n", transition.Source.id, transition.Target.id, transition.Event))
-			}
+	for _, transition := range sm.transitionsInOrder() {
+		if transition.TransType == Internal {
+			sb.WriteString(fmt.Sprintf("%v --> %v : %v%s [internal]\n", transition.Source.id, transition.Target.id, transition.Event, transition.guardLabel()))
+		} else {
+			sb.WriteString(fmt.Sprintf("%v --> %v : %v%s\n", transition.Source.id, transition.Target.id, transition.Event, transition.guardLabel()))
 		}
 	}
 
@@ -407,7 +873,7 @@ func (sm *StateMachineImpl[S, E, P]) generateMarkdownTable() string {
 
 	// States section
 	sb.WriteString("## States\n\n")
-	for stateId := range sm.stateMap {
+	for _, stateId := range sm.stateOrder {
 		sb.WriteString(fmt.Sprintf("- `%v`\n", stateId))
 	}
 	sb.WriteString("\n")
@@ -417,26 +883,9 @@ func (sm *StateMachineImpl[S, E, P]) generateMarkdownTable() string {
 	sb.WriteString("| Source State | Event | Target State | Type |\n")
 	sb.WriteString("|-------------|-------|--------------|------|\n")
 
-	// Sort states for consistent output
-	stateIds := make([]S, 0, len(sm.stateMap))
-	for stateId := range sm.stateMap {
-		stateIds = append(stateIds, stateId)
-	}
-
-	// Sort events for each state
-	for _, sourceId := range stateIds {
-		sourceState := sm.stateMap[sourceId]
-
-		for event, transitions := range sourceState.eventTransitions {
-			for _, transition := range transitions {
-				transType := "External"
-				if transition.TransType == Internal {
-					transType = "Internal"
-				}
-				sb.WriteString(fmt.Sprintf("| `%v` | `%v` | `%v` | %s |\n",
-					sourceId, event, transition.Target.id, transType))
-			}
-		}
+	for _, transition := range sm.transitionsInOrder() {
+		sb.WriteString(fmt.Sprintf("| `%v` | `%v`%s | `%v` | %s |\n",
+			transition.Source.id, transition.Event, strings.ReplaceAll(transition.guardLabel(), "|", "\\|"), transition.Target.id, transition.TransType))
 	}
 
 	return sb.String()
@@ -452,25 +901,20 @@ func (sm *StateMachineImpl[S, E, P]) generateMarkdownFlow() string {
 
 	// Define node IDs - we need to ensure they are valid Mermaid IDs
 	nodeIds := make(map[S]string)
-	i := 0
-	for stateId := range sm.stateMap {
+	for i, stateId := range sm.stateOrder {
 		// Create a valid Mermaid ID (alphanumeric and underscores only)
 		nodeIds[stateId] = fmt.Sprintf("state_%d", i)
 		sb.WriteString(fmt.Sprintf("    %s[\"%v\"]\n", nodeIds[stateId], stateId))
-		i++
 	}
 
 	// Define transitions
-	for _, state := range sm.stateMap {
-		sourceNodeId := nodeIds[state.id]
-
-		for event, transitions := range state.eventTransitions {
-			for _, transition := range transitions {
-				targetNodeId := nodeIds[transition.Target.id]
-				sb.WriteString(fmt.Sprintf("    %s -->|%v| %s\n",
-					sourceNodeId, event, targetNodeId))
-			}
+	for _, transition := range sm.transitionsInOrder() {
+		label := fmt.Sprint(transition.Event)
+		if guard := transition.guardLabel(); guard != "" {
+			label = fmt.Sprintf("%q", label+guard)
 		}
+		sb.WriteString(fmt.Sprintf("    %s -->|%s| %s\n",
+			nodeIds[transition.Source.id], label, nodeIds[transition.Target.id]))
 	}
 
 	sb.WriteString("```\n")
@@ -486,20 +930,44 @@ func (sm *StateMachineImpl[S, E, P]) generateMarkdownStateDiagram() string {
 	sb.WriteString("```mermaid\nstateDiagram-v2\n")
 
 	// Add transitions (states are automatically created in Mermaid)
-	for _, state := range sm.stateMap {
-		for event, transitions := range state.eventTransitions {
-			for _, transition := range transitions {
-				if transition.TransType == External {
-					sb.WriteString(fmt.Sprintf("    %v --> %v : %v\n",
-						transition.Source.id, transition.Target.id, event))
-				} else {
-					sb.WriteString(fmt.Sprintf("    %v --> %v : %v [internal]\n",
-						transition.Source.id, transition.Target.id, event))
-				}
-			}
+	for _, transition := range sm.transitionsInOrder() {
+		if transition.TransType == External {
+			sb.WriteString(fmt.Sprintf("    %v --> %v : %v%s\n",
+				transition.Source.id, transition.Target.id, transition.Event, transition.guardLabel()))
+		} else {
+			sb.WriteString(fmt.Sprintf("    %v --> %v : %v%s [internal]\n",
+				transition.Source.id, transition.Target.id, transition.Event, transition.guardLabel()))
 		}
 	}
 
 	sb.WriteString("```\n")
 	return sb.String()
 }
+
+// generateGraphviz returns a Graphviz DOT graph of the state machine
+func (sm *StateMachineImpl[S, E, P]) generateGraphviz() string {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("digraph %q {\n", sm.id))
+	sb.WriteString("    rankdir=LR;\n")
+
+	// Define states
+	for _, stateId := range sm.stateOrder {
+		sb.WriteString(fmt.Sprintf("    %q;\n", fmt.Sprint(stateId)))
+	}
+
+	// Define transitions, internal transitions are drawn dashed
+	for _, transition := range sm.transitionsInOrder() {
+		style := ""
+		if transition.TransType == Internal {
+			style = ", style=dashed"
+		}
+		sb.WriteString(fmt.Sprintf("    %q -> %q [label=%q%s];\n",
+			fmt.Sprint(transition.Source.id), fmt.Sprint(transition.Target.id), fmt.Sprint(transition.Event)+transition.guardLabel(), style))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,431 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImportOptions configures how a diagram is turned back into a state machine
+type ImportOptions[S comparable, E comparable, P any] struct {
+	// Registry resolves guard and action names found in transition labels
+	Registry *Registry[S, E, P]
+
+	// ParseState converts a state name from the diagram into a state
+	// If nil, names are converted directly for string and integer state types
+	ParseState func(name string) (S, error)
+
+	// ParseEvent converts an event label from the diagram into an event
+	// If nil, labels are converted directly for string and integer event types
+	ParseEvent func(label string) (E, error)
+
+	// ParseGuard compiles the guard expressions of definitions and the guards of diagram labels that are not
+	// combinations of registry names
+	// If nil, expressions are compiled with CompileExpression without field declarations
+	ParseGuard func(expression string) (Condition[P], error)
+}
+
+// parseGuard compiles a guard expression with ParseGuard or CompileExpression
+func (o ImportOptions[S, E, P]) parseGuard(expression string) (Condition[P], error) {
+	if o.ParseGuard != nil {
+		return o.ParseGuard(expression)
+	}
+	return CompileExpression[P](expression)
+}
+
+// transitionLinePattern matches "Source --> Target" with an optional ": label" suffix
+// PlantUML direction hints such as "-down->" are accepted as well, and the arrow and the colon
+// do not need to be separated from the state names by whitespace
+var transitionLinePattern = regexp.MustCompile(`^([^\s-]+?)\s*-+(?:[a-zA-Z]+-+)?>\s*([^\s:]+)\s*(?::\s*(.*))?$`)
+
+// stateDeclarationPattern matches `state "Label" as Id` and `state Id` declarations
+var stateDeclarationPattern = regexp.MustCompile(`^state\s+(?:"[^"]*"\s+as\s+)?([^\s{]+)`)
+
+// ImportDiagram parses a diagram produced by GenerateDiagram (or written by hand in the same dialect)
+// and loads its states and transitions into a new builder
+// Transition labels take the form "Event [guard] / action", where the guard and action are optional
+// and refer to names bound in the registry, and "[internal]" marks an internal transition
+// Guards may combine registry names with &&, || and ! as rendered for conditions built with And, Or and Not;
+// other guards, and parenthesized operands that are no such combination, are compiled with ParseGuard
+// Parameters:
+//
+//	format: PlantUML or MarkdownStateDiagram
+//	diagram: The diagram source
+//	options: Name resolution options
+//
+// Returns:
+//
+//	A builder holding the imported transitions and possible error
+func ImportDiagram[S comparable, E comparable, P any](format DiagramFormat, diagram string, options ImportOptions[S, E, P]) (*StateMachineBuilder[S, E, P], error) {
+	var skipLine func(line string) bool
+	switch format {
+	case PlantUML:
+		skipLine = isPlantUMLDirective
+	case MarkdownStateDiagram:
+		skipLine = isMermaidDirective
+	default:
+		return nil, ErrUnsupportedDiagramFormat
+	}
+
+	parseState := options.ParseState
+	if parseState == nil {
+		parseState = parseText[S]
+	}
+	parseEvent := options.ParseEvent
+	if parseEvent == nil {
+		parseEvent = parseText[E]
+	}
+
+	builder := NewStateMachineBuilder[S, E, P]()
+	stateMachine := builder.stateMachine
+
+	for i, rawLine := range strings.Split(diagram, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+
+		if match := stateDeclarationPattern.FindStringSubmatch(line); match != nil {
+			if match[1] == "[*]" {
+				continue
+			}
+			stateId, err := parseState(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: state %q: %v", ErrInvalidDiagram, lineNo, match[1], err)
+			}
+			stateMachine.GetState(stateId)
+			continue
+		}
+
+		if skipLine(line) {
+			continue
+		}
+
+		match := transitionLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("%w: line %d: unrecognized statement %q", ErrInvalidDiagram, lineNo, line)
+		}
+
+		// Initial and final pseudo states carry no event
+		if match[1] == "[*]" || match[2] == "[*]" {
+			continue
+		}
+
+		label, err := parseTransitionLabel(match[3])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidDiagram, lineNo, err)
+		}
+
+		sourceId, err := parseState(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: state %q: %v", ErrInvalidDiagram, lineNo, match[1], err)
+		}
+		targetId, err := parseState(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: state %q: %v", ErrInvalidDiagram, lineNo, match[2], err)
+		}
+		event, err := parseEvent(label.event)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: event %q: %v", ErrInvalidDiagram, lineNo, label.event, err)
+		}
+
+		transType := External
+		if label.internal {
+			if sourceId != targetId {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidDiagram, lineNo, ErrInternalTransition)
+			}
+			transType = Internal
+		}
+
+		var condition Condition[P]
+		if label.guard != "" {
+			condition, err = resolveGuard(options, label.guard)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+
+		var action Action[S, E, P]
+		if label.action != "" {
+			action, err = resolveAction(options.Registry, label.action)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+
+		sourceState := stateMachine.GetState(sourceId)
+		targetState := stateMachine.GetState(targetId)
+		transition := sourceState.AddTransition(event, targetState, transType)
+		transition.Condition = condition
+		transition.Action = action
+	}
+
+	return builder, nil
+}
+
+// transitionLabel holds the parts of a parsed transition label
+type transitionLabel struct {
+	event    string
+	guard    string
+	action   string
+	internal bool
+}
+
+// parseTransitionLabel splits a label of the form "Event [guard] / action [internal]"
+func parseTransitionLabel(text string) (transitionLabel, error) {
+	var label transitionLabel
+
+	// Pull out bracketed segments first, the remainder is "event / action"
+	var rest strings.Builder
+	for {
+		open := strings.Index(text, "[")
+		if open < 0 {
+			rest.WriteString(text)
+			break
+		}
+		closing := strings.Index(text[open:], "]")
+		if closing < 0 {
+			return label, fmt.Errorf("unterminated '[' in label %q", text)
+		}
+		rest.WriteString(text[:open])
+		rest.WriteString(" ")
+
+		segment := strings.TrimSpace(text[open+1 : open+closing])
+		switch {
+		case segment == "internal":
+			label.internal = true
+		case label.guard != "":
+			return label, fmt.Errorf("multiple guards in label %q", text)
+		default:
+			label.guard = segment
+		}
+		text = text[open+closing+1:]
+	}
+
+	eventPart := rest.String()
+	if slash := strings.Index(eventPart, "/"); slash >= 0 {
+		label.action = strings.TrimSpace(eventPart[slash+1:])
+		eventPart = eventPart[:slash]
+	}
+	label.event = strings.TrimSpace(eventPart)
+	if label.event == "" {
+		return label, fmt.Errorf("transition without event label")
+	}
+
+	return label, nil
+}
+
+// resolveCondition looks up a guard by name in the registry, naming it so diagrams and explanations show the name
+func resolveCondition[S comparable, E comparable, P any](registry *Registry[S, E, P], name string) (Condition[P], error) {
+	if registry != nil {
+		if condition, ok := registry.Condition(name); ok {
+			return Named(name, condition), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrConditionNotRegistered, name)
+}
+
+// resolveGuard turns the guard of a transition label back into a condition
+// Combinations of registry names are rebuilt with And, Or, Not and Named, anything else is compiled with ParseGuard
+func resolveGuard[S comparable, E comparable, P any](options ImportOptions[S, E, P], label string) (Condition[P], error) {
+	parser := &guardLabelParser[S, E, P]{options: options, text: label}
+	condition, err := parser.parse()
+	if err == nil {
+		return condition, nil
+	}
+	compiled, compileErr := options.parseGuard(label)
+	if compileErr == nil {
+		return compiled, nil
+	}
+	if errors.Is(err, ErrConditionNotRegistered) {
+		return nil, err
+	}
+	return nil, compileErr
+}
+
+// guardLabelParser parses guard labels rendered by DescribeCondition
+type guardLabelParser[S comparable, E comparable, P any] struct {
+	options ImportOptions[S, E, P]
+	text    string
+	pos     int
+}
+
+// parse parses the whole label
+func (p *guardLabelParser[S, E, P]) parse() (Condition[P], error) {
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.text) {
+		return nil, fmt.Errorf("unexpected %q in guard %q", p.text[p.pos:], p.text)
+	}
+	return condition, nil
+}
+
+// parseOr parses operands separated by ||
+func (p *guardLabelParser[S, E, P]) parseOr() (Condition[P], error) {
+	return p.parseList("||", p.parseAnd, Or[P])
+}
+
+// parseAnd parses operands separated by &&
+func (p *guardLabelParser[S, E, P]) parseAnd() (Condition[P], error) {
+	return p.parseList("&&", p.parseUnary, And[P])
+}
+
+// parseList parses operands separated by operator and combines them when there is more than one
+func (p *guardLabelParser[S, E, P]) parseList(operator string, parseOperand func() (Condition[P], error), combine func(conditions ...Condition[P]) Condition[P]) (Condition[P], error) {
+	var operands []Condition[P]
+	for {
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if !p.accept(operator) {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return combine(operands...), nil
+}
+
+// parseUnary parses an operand optionally negated with !
+func (p *guardLabelParser[S, E, P]) parseUnary() (Condition[P], error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(operand), nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a registry name or a parenthesized guard, which is resolved like a whole label
+func (p *guardLabelParser[S, E, P]) parsePrimary() (Condition[P], error) {
+	if p.accept("(") {
+		start, depth := p.pos, 1
+		for ; p.pos < len(p.text); p.pos++ {
+			switch p.text[p.pos] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if depth != 0 {
+			return nil, fmt.Errorf("unbalanced '(' in guard %q", p.text)
+		}
+		inner := p.text[start:p.pos]
+		p.pos++
+		return resolveGuard(p.options, inner)
+	}
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune(" \t&|!()", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("missing condition name in guard %q", p.text)
+	}
+	return resolveCondition(p.options.Registry, p.text[start:p.pos])
+}
+
+// accept consumes token after optional spaces and reports whether it was there
+func (p *guardLabelParser[S, E, P]) accept(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.text[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// skipSpace advances past spaces and tabs
+func (p *guardLabelParser[S, E, P]) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// resolveAction looks up an action by name in the registry
+func resolveAction[S comparable, E comparable, P any](registry *Registry[S, E, P], name string) (Action[S, E, P], error) {
+	if registry != nil {
+		if action, ok := registry.Action(name); ok {
+			return action, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrActionNotRegistered, name)
+}
+
+// isPlantUMLDirective reports whether a PlantUML line carries no states or transitions
+func isPlantUMLDirective(line string) bool {
+	if strings.HasPrefix(line, "'") || strings.HasPrefix(line, "@") || line == "}" {
+		return true
+	}
+	for _, keyword := range []string{"title", "skinparam", "hide", "left to right", "top to bottom", "note", "end note", "legend", "endlegend"} {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return isStateDescription(line)
+}
+
+// isMermaidDirective reports whether a Mermaid line carries no states or transitions
+func isMermaidDirective(line string) bool {
+	if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "%%") || line == "}" {
+		return true
+	}
+	for _, keyword := range []string{"stateDiagram", "direction", "note", "end note", "classDef", "class ", "accTitle", "accDescr"} {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return isStateDescription(line)
+}
+
+// isStateDescription reports whether a line is an "Id : description" state annotation
+func isStateDescription(line string) bool {
+	return !strings.Contains(line, "->") && strings.Contains(line, ":")
+}
+
+// parseText converts text into T for string and integer kinds
+func parseText[T comparable](text string) (T, error) {
+	var zero T
+	targetType := reflect.TypeOf(zero)
+	if targetType == nil {
+		return zero, ErrUnsupportedType
+	}
+
+	value := reflect.New(targetType).Elem()
+	switch targetType.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, targetType.Bits())
+		if err != nil {
+			return zero, err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, targetType.Bits())
+		if err != nil {
+			return zero, err
+		}
+		value.SetUint(n)
+	default:
+		return zero, fmt.Errorf("%w %s", ErrUnsupportedType, targetType)
+	}
+
+	return value.Interface().(T), nil
+}
@@ -0,0 +1,234 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestImportMermaidDiagram tests importing a hand-written Mermaid state diagram with named guards and actions
+func TestImportMermaidDiagram(t *testing.T) {
+	var actionLog []string
+	registry := NewRegistry[testState, testEvent, testPayload]().
+		RegisterConditionFunc("hasValue", func(payload testPayload) bool {
+			return payload.Value != ""
+		}).
+		RegisterActionFunc("record", func(from, to testState, event testEvent, payload testPayload) error {
+			actionLog = append(actionLog, string(from)+"->"+string(to))
+			return nil
+		})
+
+	diagram := "```mermaid\n" +
+		"stateDiagram-v2\n" +
+		"    %% designed in the wiki\n" +
+		"    [*] --> A\n" +
+		"    A --> B : Event1 [hasValue] / record\n" +
+		"    B --> B : Event2 / record [internal]\n" +
+		"    B --> C : Event3\n" +
+		"    C --> [*]\n" +
+		"```\n"
+
+	builder, err := ImportDiagram[testState, testEvent, testPayload](MarkdownStateDiagram, diagram,
+		ImportOptions[testState, testEvent, testPayload]{Registry: registry})
+	if err != nil {
+		t.Fatalf("Failed to import diagram: %v", err)
+	}
+
+	sm, err := builder.Build("ImportMermaidTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); !errors.Is(err, ErrConditionNotMet) {
+		t.Errorf("Expected ErrConditionNotMet for empty payload, got %v", err)
+	}
+
+	state, err := sm.FireEvent(StateA, Event1, testPayload{Value: "x"})
+	if err != nil || state != StateB {
+		t.Fatalf("Expected transition to %s, got %s (%v)", StateB, state, err)
+	}
+
+	state, err = sm.FireEvent(StateB, Event2, testPayload{})
+	if err != nil || state != StateB {
+		t.Fatalf("Expected internal transition to stay in %s, got %s (%v)", StateB, state, err)
+	}
+
+	state, err = sm.FireEvent(StateB, Event3, testPayload{})
+	if err != nil || state != StateC {
+		t.Fatalf("Expected transition to %s, got %s (%v)", StateC, state, err)
+	}
+
+	if len(actionLog) != 2 {
+		t.Errorf("Expected 2 actions to be executed, got %d", len(actionLog))
+	}
+}
+
+// TestImportPlantUMLRoundTrip tests that a generated PlantUML diagram can be imported back
+func TestImportPlantUMLRoundTrip(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+	builder.InternalTransition().
+		Within(StateB).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	original, err := builder.Build("ImportPlantUMLSource")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	imported, err := ImportDiagram[testState, testEvent, testPayload](PlantUML, original.GenerateDiagram(PlantUML),
+		ImportOptions[testState, testEvent, testPayload]{})
+	if err != nil {
+		t.Fatalf("Failed to import diagram: %v", err)
+	}
+
+	sm, err := imported.Build("ImportPlantUMLCopy")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected transition to %s, got %s (%v)", StateB, state, err)
+	}
+	if state, err := sm.FireEvent(StateB, Event2, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected internal transition to stay in %s, got %s (%v)", StateB, state, err)
+	}
+}
+
+// TestImportCombinedGuardsRoundTrip tests that combined and expression guards survive a diagram round trip
+// and that unnamed guards are left out of diagrams
+func TestImportCombinedGuardsRoundTrip(t *testing.T) {
+	registry := NewRegistry[testState, testEvent, testPayload]().
+		RegisterConditionFunc("hasValue", func(payload testPayload) bool { return payload.Value != "" }).
+		RegisterConditionFunc("isVIP", func(payload testPayload) bool { return payload.Value == "vip" }).
+		RegisterConditionFunc("isBlocked", func(payload testPayload) bool { return payload.Value == "blocked" })
+	named := func(name string) Condition[testPayload] {
+		condition, _ := registry.Condition(name)
+		return Named(name, condition)
+	}
+	expression, err := CompileExpression[testPayload]("payload.Value != 'c'")
+	if err != nil {
+		t.Fatalf("Failed to compile expression: %v", err)
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).
+		When(And(named("hasValue"), Or(named("isVIP"), Not(named("isBlocked"))))).
+		Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateC).On(Event2).
+		When(named("hasValue")).
+		When(expression).
+		Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateD).On(Event3).
+		When(&alwaysTrueCondition{}).
+		WhenFunc(func(payload testPayload) bool { return true }).
+		Perform(&noopAction{})
+
+	original, err := builder.Build("ImportCombinedGuardsSource")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	for _, format := range []DiagramFormat{PlantUML, MarkdownStateDiagram} {
+		diagram := original.GenerateDiagram(format)
+		if strings.Contains(diagram, "condition") || !strings.Contains(diagram, "Event1 [hasValue && (isVIP || !isBlocked)]") ||
+			!strings.Contains(diagram, "Event2 [hasValue && (payload.Value != 'c')]") {
+			t.Errorf("Unexpected guard labels:\n%s", diagram)
+		}
+
+		imported, err := ImportDiagram(format, diagram, ImportOptions[testState, testEvent, testPayload]{Registry: registry})
+		if err != nil {
+			t.Fatalf("Failed to import diagram: %v", err)
+		}
+		copyId := fmt.Sprintf("ImportCombinedGuardsCopy%v", format)
+		sm, err := imported.Build(copyId)
+		if err != nil {
+			t.Fatalf("Failed to build state machine: %v", err)
+		}
+		if copied := strings.ReplaceAll(sm.GenerateDiagram(format), copyId, "ImportCombinedGuardsSource"); copied != diagram {
+			t.Errorf("Expected the imported diagram to match the original:\n%s\n%s", diagram, copied)
+		}
+
+		if _, err := sm.FireEvent(StateA, Event1, testPayload{Value: "blocked"}); !errors.Is(err, ErrConditionNotMet) {
+			t.Errorf("Expected the combined guard to block, got %v", err)
+		}
+		if state, err := sm.FireEvent(StateA, Event1, testPayload{Value: "vip"}); err != nil || state != StateB {
+			t.Errorf("Expected B, got %v %v", state, err)
+		}
+		if _, err := sm.FireEvent(StateA, Event2, testPayload{Value: "c"}); !errors.Is(err, ErrConditionNotMet) {
+			t.Errorf("Expected the expression guard to block, got %v", err)
+		}
+		if state, err := sm.FireEvent(StateA, Event2, testPayload{Value: "d"}); err != nil || state != StateC {
+			t.Errorf("Expected C, got %v %v", state, err)
+		}
+	}
+}
+
+// TestImportDiagramErrors tests that unresolved names and malformed input are reported
+// TestImportCompactTransitions tests that arrows and labels are recognized without surrounding whitespace
+func TestImportCompactTransitions(t *testing.T) {
+	diagram := "stateDiagram-v2\n" +
+		"    [*]-->A\n" +
+		"    A-->B : Event1\n" +
+		"    B-->C:Event2\n" +
+		"    C-down->D:Event3\n" +
+		"    D-->[*]\n"
+
+	builder, err := ImportDiagram[testState, testEvent, testPayload](MarkdownStateDiagram, diagram,
+		ImportOptions[testState, testEvent, testPayload]{})
+	if err != nil {
+		t.Fatalf("Failed to import diagram: %v", err)
+	}
+
+	sm, err := builder.Build("ImportCompactTransitionsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	for _, step := range []struct {
+		from, to testState
+		event    testEvent
+	}{
+		{StateA, StateB, Event1},
+		{StateB, StateC, Event2},
+		{StateC, StateD, Event3},
+	} {
+		if state, err := sm.FireEvent(step.from, step.event, testPayload{}); err != nil || state != step.to {
+			t.Errorf("Expected %s --%s--> %s, got %s (%v)", step.from, step.event, step.to, state, err)
+		}
+	}
+}
+
+func TestImportDiagramErrors(t *testing.T) {
+	options := ImportOptions[testState, testEvent, testPayload]{}
+
+	testCases := []struct {
+		name     string
+		format   DiagramFormat
+		diagram  string
+		expected error
+	}{
+		{"Unregistered Guard", MarkdownStateDiagram, "A --> B : Event1 [missing]", ErrConditionNotRegistered},
+		{"Unregistered Action", MarkdownStateDiagram, "A --> B : Event1 / missing", ErrActionNotRegistered},
+		{"Missing Event", PlantUML, "A --> B", ErrInvalidDiagram},
+		{"Internal Between States", PlantUML, "A --> B : Event1 [internal]", ErrInvalidDiagram},
+		{"Unsupported Format", MarkdownTable, "", ErrUnsupportedDiagramFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ImportDiagram[testState, testEvent, testPayload](tc.format, tc.diagram, options)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
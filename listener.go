@@ -0,0 +1,113 @@
+package fsm
+
+import (
+	"sync"
+)
+
+// Listener observes the transitions of a state machine
+// Listeners are invoked synchronously while the state machine holds its read lock,
+// so they must not register further listeners on the same machine
+type Listener[S comparable, E comparable, P any] interface {
+	// BeforeTransition is called before the action of a selected transition runs
+	// Returning an error vetoes the transition and the error is returned from the firing call
+	BeforeTransition(from, to S, event E, payload P) error
+
+	// AfterTransition is called after the action of a transition completed successfully
+	AfterTransition(from, to S, event E, payload P)
+
+	// OnTransitionDeclined is called when an event leads nowhere: the state is unknown,
+	// no transition exists, no condition is satisfied or a listener vetoed the transition
+	OnTransitionDeclined(from S, event E, payload P, reason error)
+
+	// OnTransitionError is called when the action of a transition fails
+	OnTransitionError(from, to S, event E, payload P, err error)
+}
+
+// ListenerFuncs implements Listener with optional callbacks, nil callbacks are skipped
+type ListenerFuncs[S comparable, E comparable, P any] struct {
+	Before   func(from, to S, event E, payload P) error
+	After    func(from, to S, event E, payload P)
+	Declined func(from S, event E, payload P, reason error)
+	Error    func(from, to S, event E, payload P, err error)
+}
+
+// BeforeTransition implements Listener interface
+func (l ListenerFuncs[S, E, P]) BeforeTransition(from, to S, event E, payload P) error {
+	if l.Before == nil {
+		return nil
+	}
+	return l.Before(from, to, event, payload)
+}
+
+// AfterTransition implements Listener interface
+func (l ListenerFuncs[S, E, P]) AfterTransition(from, to S, event E, payload P) {
+	if l.After != nil {
+		l.After(from, to, event, payload)
+	}
+}
+
+// OnTransitionDeclined implements Listener interface
+func (l ListenerFuncs[S, E, P]) OnTransitionDeclined(from S, event E, payload P, reason error) {
+	if l.Declined != nil {
+		l.Declined(from, event, payload, reason)
+	}
+}
+
+// OnTransitionError implements Listener interface
+func (l ListenerFuncs[S, E, P]) OnTransitionError(from, to S, event E, payload P, err error) {
+	if l.Error != nil {
+		l.Error(from, to, event, payload, err)
+	}
+}
+
+// globalListeners holds listeners that apply to every state machine of matching type
+var globalListeners = struct {
+	listeners []interface{}
+	mutex     sync.RWMutex
+}{}
+
+// AddGlobalListener registers a listener for all state machines with the same state, event and payload types
+// Parameters:
+//
+//	listener: The listener to register
+func AddGlobalListener[S comparable, E comparable, P any](listener Listener[S, E, P]) {
+	globalListeners.mutex.Lock()
+	defer globalListeners.mutex.Unlock()
+
+	globalListeners.listeners = append(globalListeners.listeners, listener)
+}
+
+// RemoveGlobalListeners unregisters all global listeners
+func RemoveGlobalListeners() {
+	globalListeners.mutex.Lock()
+	defer globalListeners.mutex.Unlock()
+
+	globalListeners.listeners = nil
+}
+
+// AddListener registers a listener on this state machine
+func (sm *StateMachineImpl[S, E, P]) AddListener(listener Listener[S, E, P]) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.listeners = append(sm.listeners, listener)
+}
+
+// activeListeners returns the machine's listeners followed by the matching global listeners
+// The caller must hold the mutex
+func (sm *StateMachineImpl[S, E, P]) activeListeners() []Listener[S, E, P] {
+	globalListeners.mutex.RLock()
+	defer globalListeners.mutex.RUnlock()
+
+	if len(globalListeners.listeners) == 0 {
+		return sm.listeners
+	}
+
+	listeners := append([]Listener[S, E, P](nil), sm.listeners...)
+	for _, listener := range globalListeners.listeners {
+		if typed, ok := listener.(Listener[S, E, P]); ok {
+			listeners = append(listeners, typed)
+		}
+	}
+	return listeners
+}
@@ -0,0 +1,163 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// recordingListener records every callback it receives
+type recordingListener struct {
+	calls []string
+	veto  error
+}
+
+func (l *recordingListener) BeforeTransition(from, to testState, event testEvent, payload testPayload) error {
+	l.calls = append(l.calls, fmt.Sprintf("before %s->%s", from, to))
+	return l.veto
+}
+
+func (l *recordingListener) AfterTransition(from, to testState, event testEvent, payload testPayload) {
+	l.calls = append(l.calls, fmt.Sprintf("after %s->%s", from, to))
+}
+
+func (l *recordingListener) OnTransitionDeclined(from testState, event testEvent, payload testPayload, reason error) {
+	l.calls = append(l.calls, fmt.Sprintf("declined %s %s: %v", from, event, reason))
+}
+
+func (l *recordingListener) OnTransitionError(from, to testState, event testEvent, payload testPayload, err error) {
+	l.calls = append(l.calls, fmt.Sprintf("error %s->%s: %v", from, to, err))
+}
+
+// createListenerStateMachine creates a machine with a guarded, a failing and a parallel transition
+func createListenerStateMachine(t *testing.T, machineId string) StateMachine[testState, testEvent, testPayload] {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value != "blocked"
+		}).
+		Perform(&noopAction{})
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return errors.New("boom")
+		})
+	builder.ExternalParallelTransition().
+		From(StateC).
+		ToAmong(StateA, StateD).
+		On(Event3).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build(machineId)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	return sm
+}
+
+// TestListenerCallbacks tests that each firing outcome reaches the listener
+func TestListenerCallbacks(t *testing.T) {
+	sm := createListenerStateMachine(t, "ListenerCallbacks")
+	listener := &recordingListener{}
+	sm.AddListener(listener)
+
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{Value: "blocked"})
+	_, _ = sm.FireEvent(StateA, Event3, testPayload{})
+	_, _ = sm.FireEvent(StateB, Event2, testPayload{})
+	_, _ = sm.FireParallelEvent(StateC, Event3, testPayload{})
+
+	expected := []string{
+		"before A->B",
+		"after A->B",
+		"declined A Event1: transition conditions not met",
+		"declined A Event3: no transition found",
+		"before B->C",
+		"error B->C: action execution failed in ListenerCallbacks: B --Event2--> C: boom",
+		"before C->A",
+		"after C->A",
+		"before C->D",
+		"after C->D",
+	}
+	if !reflect.DeepEqual(listener.calls, expected) {
+		t.Errorf("Unexpected listener calls:\n%v\nexpected:\n%v", listener.calls, expected)
+	}
+}
+
+// TestListenerActionError tests that listeners and callers receive the error of the failed action
+func TestListenerActionError(t *testing.T) {
+	declined := errors.New("card declined")
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return declined
+		})
+
+	sm, err := builder.Build("ListenerActionErrorTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	var reported error
+	sm.AddListener(ListenerFuncs[testState, testEvent, testPayload]{
+		Error: func(from, to testState, event testEvent, payload testPayload, err error) {
+			reported = err
+		},
+	})
+
+	_, err = sm.FireEvent(StateA, Event1, testPayload{})
+	for _, err := range []error{reported, err} {
+		var transitionErr *TransitionError
+		if !errors.Is(err, declined) || !errors.Is(err, ErrActionExecutionFailed) || !errors.As(err, &transitionErr) {
+			t.Errorf("Expected a *TransitionError wrapping the action's error, got %v", err)
+		}
+	}
+}
+
+// TestListenerVeto tests that an error from BeforeTransition stops the transition
+func TestListenerVeto(t *testing.T) {
+	sm := createListenerStateMachine(t, "ListenerVeto")
+	vetoErr := errors.New("not allowed")
+	listener := &recordingListener{veto: vetoErr}
+	sm.AddListener(listener)
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); err != vetoErr {
+		t.Errorf("Expected veto error, got %v", err)
+	}
+	expected := []string{"before A->B", "declined A Event1: not allowed"}
+	if !reflect.DeepEqual(listener.calls, expected) {
+		t.Errorf("Unexpected listener calls: %v", listener.calls)
+	}
+}
+
+// TestGlobalListener tests that global listeners only observe machines of their type
+func TestGlobalListener(t *testing.T) {
+	t.Cleanup(RemoveGlobalListeners)
+
+	var declined []string
+	AddGlobalListener[testState, testEvent, testPayload](ListenerFuncs[testState, testEvent, testPayload]{
+		Declined: func(from testState, event testEvent, payload testPayload, reason error) {
+			declined = append(declined, string(event))
+		},
+	})
+	AddGlobalListener[string, string, any](ListenerFuncs[string, string, any]{
+		Declined: func(from string, event string, payload any, reason error) {
+			t.Error("Listener of a different type must not be invoked")
+		},
+	})
+
+	sm := createListenerStateMachine(t, "GlobalListener")
+	_, _ = sm.FireEvent(StateA, Event2, testPayload{})
+
+	if !reflect.DeepEqual(declined, []string{"Event2"}) {
+		t.Errorf("Unexpected declined events: %v", declined)
+	}
+}
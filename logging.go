@@ -0,0 +1,118 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LogLevel is the severity of a log record, values match log/slog levels
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = -4
+	LevelInfo  LogLevel = 0
+	LevelWarn  LogLevel = 4
+	LevelError LogLevel = 8
+)
+
+// String returns the name of the level
+func (l LogLevel) String() string {
+	switch {
+	case l <= LevelDebug:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// LogRecord describes one firing, or one taken transition of a parallel firing
+type LogRecord struct {
+	Level     LogLevel
+	Message   string
+	MachineId string
+	From      string
+	To        string // empty when no transition was taken
+	Event     string
+	Type      string // "External" or "Internal", empty when no transition was selected
+	Duration  time.Duration
+	Outcome   FireOutcome
+	Err       error // error of the firing, a failed action's own error is wrapped in a *TransitionError
+}
+
+// Logger receives a structured record for every firing
+type Logger interface {
+	Log(ctx context.Context, record LogRecord)
+}
+
+// LoggerFunc is a function type that implements Logger interface
+type LoggerFunc func(ctx context.Context, record LogRecord)
+
+// Log implements Logger interface
+func (f LoggerFunc) Log(ctx context.Context, record LogRecord) {
+	f(ctx, record)
+}
+
+// LevelPolicy chooses the level of a record from the firing outcome
+type LevelPolicy func(outcome FireOutcome, err error) LogLevel
+
+// DefaultLevelPolicy logs taken transitions at info, declined events at debug and failures at error
+func DefaultLevelPolicy(outcome FireOutcome, err error) LogLevel {
+	switch outcome {
+	case OutcomeSuccess:
+		return LevelInfo
+	case OutcomeDeclined:
+		return LevelDebug
+	default:
+		return LevelError
+	}
+}
+
+// Log messages used by state machines
+const (
+	MessageTransitionCompleted = "transition completed"
+	MessageEventDeclined       = "event declined"
+	MessageTransitionFailed    = "transition failed"
+)
+
+// logFiring emits the records of a completed firing
+// taken holds the executed transitions on success, or the failing transition (if any) on error
+func (sm *StateMachineImpl[S, E, P]) logFiring(ctx context.Context, sourceStateId S, event E, taken []*Transition[S, E, P], err error, duration time.Duration) {
+	policy := sm.logLevels
+	if policy == nil {
+		policy = DefaultLevelPolicy
+	}
+
+	outcome := outcomeOf(err)
+	record := LogRecord{
+		Level:     policy(outcome, err),
+		MachineId: sm.id,
+		From:      fmt.Sprint(sourceStateId),
+		Event:     fmt.Sprint(event),
+		Duration:  duration,
+		Outcome:   outcome,
+		Err:       err,
+	}
+	switch outcome {
+	case OutcomeSuccess:
+		record.Message = MessageTransitionCompleted
+	case OutcomeDeclined:
+		record.Message = MessageEventDeclined
+	default:
+		record.Message = MessageTransitionFailed
+	}
+
+	if len(taken) == 0 {
+		sm.logger.Log(ctx, record)
+		return
+	}
+	for _, transition := range taken {
+		record.To = fmt.Sprint(transition.Target.GetID())
+		record.Type = transition.TransType.String()
+		sm.logger.Log(ctx, record)
+	}
+}
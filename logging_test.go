@@ -0,0 +1,103 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestLoggerRecords tests that every firing emits a structured record with the level chosen by the policy
+func TestLoggerRecords(t *testing.T) {
+	var records []LogRecord
+	logger := LoggerFunc(func(ctx context.Context, record LogRecord) {
+		records = append(records, record)
+	})
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithLogger(logger)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+	diskFull := errors.New("disk full")
+	builder.InternalTransition().
+		Within(StateB).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return diskFull
+		})
+
+	sm, err := builder.Build("LoggingTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateA, Event3, testPayload{})
+	_, _ = sm.FireEvent(StateB, Event2, testPayload{})
+
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	testCases := []struct {
+		record  LogRecord
+		level   LogLevel
+		message string
+		to      string
+		kind    string
+		outcome FireOutcome
+	}{
+		{records[0], LevelInfo, MessageTransitionCompleted, "B", "External", OutcomeSuccess},
+		{records[1], LevelDebug, MessageEventDeclined, "", "", OutcomeDeclined},
+		{records[2], LevelError, MessageTransitionFailed, "B", "Internal", OutcomeError},
+	}
+	for i, tc := range testCases {
+		if tc.record.Level != tc.level || tc.record.Message != tc.message || tc.record.To != tc.to ||
+			tc.record.Type != tc.kind || tc.record.Outcome != tc.outcome || tc.record.MachineId != "LoggingTest" {
+			t.Errorf("Unexpected record %d: %+v", i, tc.record)
+		}
+	}
+	if !errors.Is(records[1].Err, ErrTransitionNotFound) {
+		t.Errorf("Expected declined record to carry ErrTransitionNotFound, got %v", records[1].Err)
+	}
+	if !errors.Is(records[2].Err, diskFull) || !errors.Is(records[2].Err, ErrActionExecutionFailed) {
+		t.Errorf("Expected failed record to carry the action's error, got %v", records[2].Err)
+	}
+}
+
+// TestLoggerLevelPolicy tests that a custom level policy overrides the default levels
+func TestLoggerLevelPolicy(t *testing.T) {
+	var levels []LogLevel
+	logger := LoggerFunc(func(ctx context.Context, record LogRecord) {
+		levels = append(levels, record.Level)
+	})
+	policy := func(outcome FireOutcome, err error) LogLevel {
+		if outcome == OutcomeDeclined {
+			return LevelWarn
+		}
+		return DefaultLevelPolicy(outcome, err)
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithLogger(logger, policy)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("LoggingPolicyTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateB, Event1, testPayload{})
+
+	if len(levels) != 2 || levels[0] != LevelInfo || levels[1] != LevelWarn {
+		t.Errorf("Expected [INFO WARN], got %v", levels)
+	}
+}
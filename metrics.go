@@ -0,0 +1,296 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FireOutcome classifies the result of firing an event
+type FireOutcome string
+
+const (
+	// OutcomeSuccess means a transition was taken
+	OutcomeSuccess FireOutcome = "success"
+	// OutcomeDeclined means the event led nowhere: unknown state, no transition or no satisfied condition
+	OutcomeDeclined FireOutcome = "declined"
+	// OutcomeError means the transition failed, for example because its action returned an error
+	OutcomeError FireOutcome = "error"
+)
+
+// outcomeOf classifies a firing error
+func outcomeOf(err error) FireOutcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrStateNotFound), errors.Is(err, ErrTransitionNotFound), errors.Is(err, ErrConditionNotMet):
+		return OutcomeDeclined
+	default:
+		return OutcomeError
+	}
+}
+
+// Metrics receives measurements from state machines
+// States and events are passed in their fmt.Sprint form so one implementation can serve machines of any type
+type Metrics interface {
+	// ObserveFiring is called once for every fired event, parallel firings included
+	// targets are the states the machine moved to in execution order, including the completed branches
+	// of a failed parallel firing that were not compensated
+	ObserveFiring(machineId, source, event string, targets []string, outcome FireOutcome, duration time.Duration)
+
+	// ObserveCondition is called for every condition evaluated while firing
+	ObserveCondition(machineId, source, event string, duration time.Duration)
+
+	// ObserveAction is called for every action executed while firing
+	ObserveAction(machineId, source, event, target string, duration time.Duration, err error)
+}
+
+// DefaultLatencyBuckets are the histogram buckets, in seconds, used when none are given
+var DefaultLatencyBuckets = []float64{0.00001, 0.0001, 0.001, 0.01, 0.1, 1, 10}
+
+// InMemoryMetrics collects metrics in process and serves them in the Prometheus text exposition format
+type InMemoryMetrics struct {
+	buckets    []float64
+	counters   map[string]*counterSeries
+	histograms map[string]*histogramSeries
+	mutex      sync.Mutex
+}
+
+// counterSeries is one labeled counter
+type counterSeries struct {
+	name   string
+	labels []string // alternating label names and values
+	value  uint64
+}
+
+// histogramSeries is one labeled histogram
+type histogramSeries struct {
+	name   string
+	labels []string // alternating label names and values
+	counts []uint64 // cumulative count per bucket
+	sum    float64
+	count  uint64
+}
+
+// metricHelp documents the exported metric families
+var metricHelp = map[string]string{
+	"fsm_events_total":               "Number of fired events by source, event, target and outcome.",
+	"fsm_transitions_total":          "Number of transitions taken by source, event and target.",
+	"fsm_action_errors_total":        "Number of failed actions.",
+	"fsm_fire_duration_seconds":      "Time taken to fire an event.",
+	"fsm_condition_duration_seconds": "Time taken to evaluate a condition.",
+	"fsm_action_duration_seconds":    "Time taken to execute an action.",
+}
+
+// NewInMemoryMetrics creates an in-process metrics collector
+// Parameters:
+//
+//	buckets: Upper bounds of the latency histogram buckets in seconds, DefaultLatencyBuckets if empty
+//
+// Returns:
+//
+//	A new metrics collector
+func NewInMemoryMetrics(buckets ...float64) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &InMemoryMetrics{
+		buckets:    sorted,
+		counters:   make(map[string]*counterSeries),
+		histograms: make(map[string]*histogramSeries),
+	}
+}
+
+// ObserveFiring implements Metrics interface
+// Events are counted once per firing, labelled with the first target on success and no target otherwise,
+// and every target the machine moved to is counted separately in fsm_transitions_total
+func (m *InMemoryMetrics) ObserveFiring(machineId, source, event string, targets []string, outcome FireOutcome, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	target := ""
+	if outcome == OutcomeSuccess && len(targets) > 0 {
+		target = targets[0]
+	}
+	m.addCounter("fsm_events_total",
+		"machine", machineId, "source", source, "event", event, "target", target, "outcome", string(outcome))
+	for _, target := range targets {
+		m.addCounter("fsm_transitions_total", "machine", machineId, "source", source, "event", event, "target", target)
+	}
+	m.observeHistogram("fsm_fire_duration_seconds", duration,
+		"machine", machineId, "source", source, "event", event)
+}
+
+// ObserveCondition implements Metrics interface
+func (m *InMemoryMetrics) ObserveCondition(machineId, source, event string, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.observeHistogram("fsm_condition_duration_seconds", duration,
+		"machine", machineId, "source", source, "event", event)
+}
+
+// ObserveAction implements Metrics interface
+func (m *InMemoryMetrics) ObserveAction(machineId, source, event, target string, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.observeHistogram("fsm_action_duration_seconds", duration,
+		"machine", machineId, "source", source, "event", event, "target", target)
+	if err != nil {
+		m.addCounter("fsm_action_errors_total",
+			"machine", machineId, "source", source, "event", event, "target", target)
+	}
+}
+
+// EventCount returns how many times an event was fired with the given labels and outcome
+func (m *InMemoryMetrics) EventCount(machineId, source, event, target string, outcome FireOutcome) uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := seriesKey("fsm_events_total",
+		[]string{"machine", machineId, "source", source, "event", event, "target", target, "outcome", string(outcome)})
+	if series, ok := m.counters[key]; ok {
+		return series.value
+	}
+	return 0
+}
+
+// TransitionCount returns how many times a transition to target was taken for an event from source
+func (m *InMemoryMetrics) TransitionCount(machineId, source, event, target string) uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := seriesKey("fsm_transitions_total",
+		[]string{"machine", machineId, "source", source, "event", event, "target", target})
+	if series, ok := m.counters[key]; ok {
+		return series.value
+	}
+	return 0
+}
+
+// addCounter increments a counter series; the caller must hold the mutex
+func (m *InMemoryMetrics) addCounter(name string, labels ...string) {
+	key := seriesKey(name, labels)
+	series, ok := m.counters[key]
+	if !ok {
+		series = &counterSeries{name: name, labels: labels}
+		m.counters[key] = series
+	}
+	series.value++
+}
+
+// observeHistogram records a duration in a histogram series; the caller must hold the mutex
+func (m *InMemoryMetrics) observeHistogram(name string, duration time.Duration, labels ...string) {
+	key := seriesKey(name, labels)
+	series, ok := m.histograms[key]
+	if !ok {
+		series = &histogramSeries{name: name, labels: labels, counts: make([]uint64, len(m.buckets))}
+		m.histograms[key] = series
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			series.counts[i]++
+		}
+	}
+	series.sum += seconds
+	series.count++
+}
+
+// WritePrometheus writes all collected metrics in the Prometheus text exposition format
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var sb strings.Builder
+
+	counterKeys := sortedKeys(m.counters)
+	lastName := ""
+	for _, key := range counterKeys {
+		series := m.counters[key]
+		if series.name != lastName {
+			writeMetricHeader(&sb, series.name, "counter")
+			lastName = series.name
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %d\n", series.name, formatLabels(series.labels), series.value))
+	}
+
+	histogramKeys := sortedKeys(m.histograms)
+	lastName = ""
+	for _, key := range histogramKeys {
+		series := m.histograms[key]
+		if series.name != lastName {
+			writeMetricHeader(&sb, series.name, "histogram")
+			lastName = series.name
+		}
+		for i, bound := range m.buckets {
+			labels := append(append([]string(nil), series.labels...), "le", strconv.FormatFloat(bound, 'g', -1, 64))
+			sb.WriteString(fmt.Sprintf("%s_bucket%s %d\n", series.name, formatLabels(labels), series.counts[i]))
+		}
+		labels := append(append([]string(nil), series.labels...), "le", "+Inf")
+		sb.WriteString(fmt.Sprintf("%s_bucket%s %d\n", series.name, formatLabels(labels), series.count))
+		sb.WriteString(fmt.Sprintf("%s_sum%s %s\n", series.name, formatLabels(series.labels), strconv.FormatFloat(series.sum, 'g', -1, 64)))
+		sb.WriteString(fmt.Sprintf("%s_count%s %d\n", series.name, formatLabels(series.labels), series.count))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ServeHTTP implements http.Handler, serving the metrics in the Prometheus text exposition format
+func (m *InMemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric family
+func writeMetricHeader(sb *strings.Builder, name, metricType string) {
+	sb.WriteString(fmt.Sprintf("# HELP %s %s\n", name, metricHelp[name]))
+	sb.WriteString(fmt.Sprintf("# TYPE %s %s\n", name, metricType))
+}
+
+// seriesKey identifies a series by metric name and label values
+func seriesKey(name string, labels []string) string {
+	return name + "\xff" + strings.Join(labels, "\xff")
+}
+
+// formatLabels renders alternating label names and values as {name="value",...}
+func formatLabels(labels []string) string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(labels[i+1]))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// labelEscaper escapes label values as required by the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
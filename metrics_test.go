@@ -0,0 +1,130 @@
+package fsm
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestInMemoryMetrics tests that firings are counted by outcome and exposed in the Prometheus format
+func TestInMemoryMetrics(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithMetrics(metrics)
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value != "no"
+		}).
+		Perform(&noopAction{})
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return errors.New("gateway down")
+		})
+
+	sm, err := builder.Build("MetricsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{Value: "no"})
+	_, _ = sm.FireEvent(StateA, Event3, testPayload{})
+	_, _ = sm.FireEvent(StateB, Event2, testPayload{})
+
+	testCases := []struct {
+		source   testState
+		event    testEvent
+		target   testState
+		outcome  FireOutcome
+		expected uint64
+	}{
+		{StateA, Event1, StateB, OutcomeSuccess, 2},
+		{StateA, Event1, "", OutcomeDeclined, 1},
+		{StateA, Event3, "", OutcomeDeclined, 1},
+		{StateB, Event2, "", OutcomeError, 1},
+	}
+	for _, tc := range testCases {
+		count := metrics.EventCount("MetricsTest", string(tc.source), string(tc.event), string(tc.target), tc.outcome)
+		if count != tc.expected {
+			t.Errorf("Expected %d %s firings of %s from %s, got %d", tc.expected, tc.outcome, tc.event, tc.source, count)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, expected := range []string{
+		"# TYPE fsm_events_total counter",
+		`fsm_events_total{machine="MetricsTest",source="A",event="Event1",target="B",outcome="success"} 2`,
+		`fsm_action_errors_total{machine="MetricsTest",source="B",event="Event2",target="C"} 1`,
+		"# TYPE fsm_condition_duration_seconds histogram",
+		`fsm_condition_duration_seconds_count{machine="MetricsTest",source="A",event="Event1"} 3`,
+		`fsm_action_duration_seconds_bucket{machine="MetricsTest",source="A",event="Event1",target="B",le="+Inf"} 2`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+// TestInMemoryMetricsParallel tests that parallel firings are observed once and their targets counted separately
+func TestInMemoryMetricsParallel(t *testing.T) {
+	metrics := NewInMemoryMetrics()
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().
+		WithMetrics(metrics).
+		WithParallelExecution(ParallelOptions{})
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC, StateD).
+		On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			if to == StateD && payload.Value == "fail" {
+				return errors.New("gateway down")
+			}
+			return nil
+		})
+
+	sm, err := builder.Build("MetricsParallelTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, _ = sm.FireParallelEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireParallelEvent(StateA, Event1, testPayload{Value: "fail"})
+
+	if count := metrics.EventCount("MetricsParallelTest", "A", "Event1", "B", OutcomeSuccess); count != 1 {
+		t.Errorf("Expected 1 successful firing, got %d", count)
+	}
+	if count := metrics.EventCount("MetricsParallelTest", "A", "Event1", "", OutcomeError); count != 1 {
+		t.Errorf("Expected 1 failed firing, got %d", count)
+	}
+	for target, expected := range map[string]uint64{"B": 2, "C": 2, "D": 1} {
+		if count := metrics.TransitionCount("MetricsParallelTest", "A", "Event1", target); count != expected {
+			t.Errorf("Expected %d transitions to %s, got %d", expected, target, count)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if expected := `fsm_fire_duration_seconds_count{machine="MetricsParallelTest",source="A",event="Event1"} 2`; !strings.Contains(recorder.Body.String(), expected) {
+		t.Errorf("Expected exposition to contain %q, got:\n%s", expected, recorder.Body.String())
+	}
+}
+
+// TestFormatLabelsEscaping tests that label values are escaped
+func TestFormatLabelsEscaping(t *testing.T) {
+	labels := formatLabels([]string{"state", "a\"b\\c\nd"})
+	if labels != `{state="a\"b\\c\nd"}` {
+		t.Errorf("Unexpected escaped labels: %s", labels)
+	}
+}
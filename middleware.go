@@ -0,0 +1,39 @@
+package fsm
+
+import (
+	"context"
+)
+
+// FireFunc fires an event from a source state and returns the resulting state
+type FireFunc[S comparable, E comparable, P any] func(ctx context.Context, sourceState S, event E, payload P) (S, error)
+
+// Middleware wraps a FireFunc to add cross-cutting behavior such as logging, authorization,
+// metrics, payload enrichment or panic recovery
+// A middleware may inspect or replace the arguments before calling next,
+// inspect or replace the resulting state and error afterwards, or not call next at all
+type Middleware[S comparable, E comparable, P any] func(next FireFunc[S, E, P]) FireFunc[S, E, P]
+
+// Use appends middleware around FireEvent and FireEventContext
+// Parameters:
+//
+//	middleware: Middleware in order from outermost to innermost
+func (sm *StateMachineImpl[S, E, P]) Use(middleware ...Middleware[S, E, P]) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.middleware = append(sm.middleware, middleware...)
+
+	// Compose once here so that firing only pays for the middleware itself
+	sm.fireChain = composeMiddleware(sm.middleware, func(ctx context.Context, sourceStateId S, event E, payload P) (S, error) {
+		return sm.fireEvent(ctx, sourceStateId, event, payload, nil)
+	})
+}
+
+// composeMiddleware wraps core in the middleware, the first one outermost
+func composeMiddleware[S comparable, E comparable, P any](middleware []Middleware[S, E, P], core FireFunc[S, E, P]) FireFunc[S, E, P] {
+	chain := core
+	for i := len(middleware) - 1; i >= 0; i-- {
+		chain = middleware[i](chain)
+	}
+	return chain
+}
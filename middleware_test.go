@@ -0,0 +1,70 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type contextKey string
+
+// TestMiddlewareOrder tests that middleware wraps FireEvent from the outside in
+func TestMiddlewareOrder(t *testing.T) {
+	sm := createQueryStateMachine(t, "MiddlewareOrder")
+
+	var trace []string
+	tracing := func(name string) Middleware[testState, testEvent, testPayload] {
+		return func(next FireFunc[testState, testEvent, testPayload]) FireFunc[testState, testEvent, testPayload] {
+			return func(ctx context.Context, source testState, event testEvent, payload testPayload) (testState, error) {
+				trace = append(trace, name+" in")
+				target, err := next(ctx, source, event, payload)
+				trace = append(trace, name+" out "+string(target))
+				return target, err
+			}
+		}
+	}
+	sm.Use(tracing("outer"), tracing("inner"))
+
+	if _, err := sm.FireEvent(StateA, Event3, testPayload{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"outer in", "inner in", "inner out C", "outer out C"}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("Expected %v, got %v", expected, trace)
+	}
+}
+
+// TestMiddlewareContextAndPayload tests authorization from the context and payload enrichment
+func TestMiddlewareContextAndPayload(t *testing.T) {
+	sm := createQueryStateMachine(t, "MiddlewareContext")
+	errUnauthorized := errors.New("unauthorized")
+
+	sm.Use(
+		func(next FireFunc[testState, testEvent, testPayload]) FireFunc[testState, testEvent, testPayload] {
+			return func(ctx context.Context, source testState, event testEvent, payload testPayload) (testState, error) {
+				if ctx.Value(contextKey("user")) == nil {
+					return source, errUnauthorized
+				}
+				return next(ctx, source, event, payload)
+			}
+		},
+		func(next FireFunc[testState, testEvent, testPayload]) FireFunc[testState, testEvent, testPayload] {
+			return func(ctx context.Context, source testState, event testEvent, payload testPayload) (testState, error) {
+				payload.Value = "ok" // satisfies the guard of Event1
+				return next(ctx, source, event, payload)
+			}
+		},
+	)
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); err != errUnauthorized {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), contextKey("user"), "alice")
+	state, err := sm.FireEventContext(ctx, StateA, Event1, testPayload{})
+	if err != nil || state != StateB {
+		t.Errorf("Expected enriched payload to reach %s, got %s (%v)", StateB, state, err)
+	}
+}
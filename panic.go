@@ -0,0 +1,96 @@
+package fsm
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Phases in which a panic can be recovered
+const (
+	PhaseCondition = "condition"
+	PhaseAction    = "action"
+)
+
+// PanicError is returned instead of crashing when a condition or action panics and panic recovery is enabled
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+	// Phase is PhaseCondition or PhaseAction
+	Phase     string
+	MachineId string
+	Source    string
+	Target    string
+	Event     string
+}
+
+// Error implements error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s of %s: %s --%s--> %s: %v", e.Phase, e.MachineId, e.Source, e.Event, e.Target, e.Value)
+}
+
+// Is reports whether the panic happened in an action, so callers checking ErrActionExecutionFailed see it
+func (e *PanicError) Is(target error) bool {
+	return target == ErrActionExecutionFailed && e.Phase == PhaseAction
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// PanicHandler is notified of every recovered panic, for example to report it to an error tracker
+type PanicHandler func(err *PanicError)
+
+// protect runs fn, converting a panic into a *PanicError when panic recovery is enabled
+func (sm *StateMachineImpl[S, E, P]) protect(phase string, transition *Transition[S, E, P], fn func()) (err error) {
+	if !sm.recoverPanics {
+		fn()
+		return nil
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			panicErr := &PanicError{
+				Value:     value,
+				Stack:     debug.Stack(),
+				Phase:     phase,
+				MachineId: sm.id,
+				Source:    fmt.Sprint(transition.Source.GetID()),
+				Target:    fmt.Sprint(transition.Target.GetID()),
+				Event:     fmt.Sprint(transition.Event),
+			}
+			if sm.panicHandler != nil {
+				sm.panicHandler(panicErr)
+			}
+			err = panicErr
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// goroutinePanic carries a panic out of a goroutine spawned by the state machine
+// Without panic recovery it is raised again on the calling goroutine, where the caller's recover can see it
+type goroutinePanic struct {
+	value interface{}
+}
+
+// capturePanic stores a panic of the current goroutine in p, it must be deferred directly
+func capturePanic(p **goroutinePanic) {
+	if value := recover(); value != nil {
+		*p = &goroutinePanic{value: value}
+	}
+}
+
+// raise panics again with the captured value, nothing happens when p is nil
+func (p *goroutinePanic) raise() {
+	if p != nil {
+		panic(p.value)
+	}
+}
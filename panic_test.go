@@ -0,0 +1,138 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestPanicRecovery tests that panics in conditions and actions are returned as *PanicError and reported
+func TestPanicRecovery(t *testing.T) {
+	var reported []*PanicError
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().
+		WithPanicRecovery(func(err *PanicError) {
+			reported = append(reported, err)
+		})
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			panic("guard exploded")
+		}).
+		Perform(&noopAction{})
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			var values map[string]int
+			values["boom"]++
+			return nil
+		})
+
+	sm, err := builder.Build("PanicRecoveryTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, err = sm.FireEvent(StateA, Event1, testPayload{})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected *PanicError, got %v", err)
+	}
+	if panicErr.Phase != PhaseCondition || panicErr.Value != "guard exploded" ||
+		panicErr.Source != "A" || panicErr.Target != "B" || panicErr.Event != "Event1" {
+		t.Errorf("Unexpected panic details: %+v", panicErr)
+	}
+	if !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+		t.Error("Expected stack trace to point at the panicking condition")
+	}
+	if errors.Is(err, ErrActionExecutionFailed) {
+		t.Error("Condition panic should not match ErrActionExecutionFailed")
+	}
+
+	_, err = sm.FireEvent(StateB, Event2, testPayload{})
+	if !errors.As(err, &panicErr) || panicErr.Phase != PhaseAction {
+		t.Fatalf("Expected action *PanicError, got %v", err)
+	}
+	if !errors.Is(err, ErrActionExecutionFailed) {
+		t.Error("Action panic should match ErrActionExecutionFailed")
+	}
+	var runtimeErr interface{ RuntimeError() }
+	if !errors.As(err, &runtimeErr) {
+		t.Error("Expected the runtime error to be unwrapped")
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("Expected 2 reported panics, got %d", len(reported))
+	}
+}
+
+// TestPanicWithoutRecovery tests that panics propagate unless recovery is enabled
+func TestPanicWithoutRecovery(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			panic("guard exploded")
+		}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("PanicPropagationTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic to propagate")
+		}
+		// The read lock must have been released while unwinding
+		sm.AddListener(ListenerFuncs[testState, testEvent, testPayload]{})
+	}()
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+}
+
+// TestParallelPanicPartialResults tests that branches completed before a panicking branch are reported
+func TestParallelPanicPartialResults(t *testing.T) {
+	var failed []testState
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithPanicRecovery()
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC, StateD).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			if to == StateC {
+				panic("branch exploded")
+			}
+			return nil
+		})
+
+	sm, err := builder.Build("ParallelPanicTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	sm.AddListener(ListenerFuncs[testState, testEvent, testPayload]{
+		Error: func(from, to testState, event testEvent, payload testPayload, err error) {
+			failed = append(failed, to)
+		},
+	})
+
+	targets, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Target != "C" {
+		t.Fatalf("Expected *PanicError for branch C, got %v", err)
+	}
+	if len(targets) != 1 || targets[0] != StateB {
+		t.Errorf("Expected completed branch [B], got %v", targets)
+	}
+	if len(failed) != 1 || failed[0] != StateC {
+		t.Errorf("Expected listeners to be told branch C failed, got %v", failed)
+	}
+}
@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParallelOptions configures concurrent execution of parallel transition branches
+type ParallelOptions struct {
+	// MaxConcurrency limits how many branch actions run at the same time, 0 means no limit
+	MaxConcurrency int
+	// FailFast cancels the context of the other branches once one fails, branches not started yet are skipped
+	FailFast bool
+}
+
+// ParallelError collects the failures of concurrently executed parallel branches, keyed by target state
+type ParallelError[S comparable] struct {
+	Errors map[S]error
+}
+
+// Error implements error interface
+func (e *ParallelError[S]) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for target, err := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%v: %v", target, err))
+	}
+	sort.Strings(messages)
+	return "parallel transition failed: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether any branch error matches target
+func (e *ParallelError[S]) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first branch error, in target state order, that matches target
+func (e *ParallelError[S]) As(target interface{}) bool {
+	for _, err := range e.sortedErrors() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the branch errors, in target state order
+func (e *ParallelError[S]) Unwrap() []error {
+	return e.sortedErrors()
+}
+
+// sortedErrors returns the branch errors ordered by the printed target state
+func (e *ParallelError[S]) sortedErrors() []error {
+	targets := make([]S, 0, len(e.Errors))
+	for target := range e.Errors {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return fmt.Sprint(targets[i]) < fmt.Sprint(targets[j])
+	})
+
+	errs := make([]error, 0, len(targets))
+	for _, target := range targets {
+		errs = append(errs, e.Errors[target])
+	}
+	return errs
+}
+
+// branchOutcome is the result of one concurrently executed branch
+type branchOutcome[S comparable, E comparable, P any] struct {
+	executed *Transition[S, E, P]
+	duration time.Duration
+	err      error
+	skipped  bool
+}
+
+// transitConcurrently executes the selected parallel transitions in goroutines
+// It returns the targets of the successful branches in declaration order, the transitions that were executed
+// or failed, and a *ParallelError when any branch failed, wrapped in a *CompensationError
+// when successful branches were compensated
+func (sm *StateMachineImpl[S, E, P]) transitConcurrently(f *firing[S, E], listeners []Listener[S, E, P], selected []*Transition[S, E, P], payload P) ([]S, []*Transition[S, E, P], error) {
+	options := sm.parallelOptions
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	// The branches share the value stash, so it must exist before they start
+	if f.scope == nil {
+		f.scope = &firingScope[E]{}
+	}
+	branch := &firing[S, E]{ctx: ctx, parallel: true, result: f.result, scope: f.scope}
+
+	var semaphore chan struct{}
+	if options.MaxConcurrency > 0 {
+		semaphore = make(chan struct{}, options.MaxConcurrency)
+	}
+
+	outcomes := make([]branchOutcome[S, E, P], len(selected))
+	panics := make([]*goroutinePanic, len(selected))
+	var wg sync.WaitGroup
+	for i, transition := range selected {
+		wg.Add(1)
+		go func(i int, transition *Transition[S, E, P]) {
+			defer wg.Done()
+			defer capturePanic(&panics[i])
+
+			if semaphore != nil {
+				select {
+				case semaphore <- struct{}{}:
+					defer func() { <-semaphore }()
+				case <-ctx.Done():
+					outcomes[i] = branchOutcome[S, E, P]{err: ctx.Err(), skipped: true}
+					return
+				}
+			}
+			// Branches that have not started yet are skipped once the firing is cancelled
+			if ctx.Err() != nil {
+				outcomes[i] = branchOutcome[S, E, P]{err: ctx.Err(), skipped: true}
+				return
+			}
+
+			executed, duration, err := sm.transit(branch, listeners, transition, payload)
+			outcomes[i] = branchOutcome[S, E, P]{executed: executed, duration: duration, err: err}
+			if err != nil && options.FailFast {
+				cancel()
+			}
+		}(i, transition)
+	}
+	wg.Wait()
+
+	// A panic that panic recovery did not convert is raised on the caller's goroutine, as without concurrency
+	for _, panicked := range panics {
+		panicked.raise()
+	}
+
+	var targets []S
+	var taken, failed []*Transition[S, E, P]
+	parallelErr := &ParallelError[S]{Errors: make(map[S]error)}
+	for i, outcome := range outcomes {
+		transition := selected[i]
+		if outcome.err != nil {
+			parallelErr.Errors[transition.Target.GetID()] = outcome.err
+			if !outcome.skipped {
+				failed = append(failed, transition)
+			}
+			continue
+		}
+		taken = append(taken, outcome.executed)
+		targets = append(targets, outcome.executed.Target.GetID())
+		if f.result != nil {
+			f.result.record(outcome.executed.Info(), outcome.duration)
+		}
+	}
+
+	if len(parallelErr.Errors) > 0 {
+		targets, err := sm.compensate(f, taken, payload, parallelErr)
+		return targets, failed, err
+	}
+	return targets, taken, nil
+}
@@ -0,0 +1,216 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// buildConcurrentMachine creates a machine with a parallel transition from A to B, C and D
+func buildConcurrentMachine(t *testing.T, machineId string, options ParallelOptions, action ContextualActionFunc[testState, testEvent, testPayload]) StateMachine[testState, testEvent, testPayload] {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithParallelExecution(options)
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC, StateD).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(action)
+
+	sm, err := builder.Build(machineId)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	return sm
+}
+
+// TestParallelExecutionConcurrent tests that branches run at the same time and report in declaration order
+func TestParallelExecutionConcurrent(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(3)
+	sm := buildConcurrentMachine(t, "ParallelConcurrentTest", ParallelOptions{},
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			// Every branch waits for the others, which only finishes if they run concurrently
+			started.Done()
+			started.Wait()
+			return nil
+		})
+
+	result, err := sm.FireParallel(StateA, Event1, testPayload{})
+	if err != nil {
+		t.Fatalf("Failed to fire parallel event: %v", err)
+	}
+	if len(result.Targets) != 3 || result.Targets[0] != StateB || result.Targets[1] != StateC || result.Targets[2] != StateD {
+		t.Errorf("Expected targets [B C D], got %v", result.Targets)
+	}
+}
+
+// TestParallelExecutionConcurrencyLimit tests that no more branches run at once than allowed
+func TestParallelExecutionConcurrencyLimit(t *testing.T) {
+	var running, peak int32
+	sm := buildConcurrentMachine(t, "ParallelLimitTest", ParallelOptions{MaxConcurrency: 1},
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			current := atomic.AddInt32(&running, 1)
+			if current > atomic.LoadInt32(&peak) {
+				atomic.StoreInt32(&peak, current)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+
+	if _, err := sm.FireParallelEvent(StateA, Event1, testPayload{}); err != nil {
+		t.Fatalf("Failed to fire parallel event: %v", err)
+	}
+	if peak != 1 {
+		t.Errorf("Expected at most 1 branch at a time, got %d", peak)
+	}
+}
+
+// TestParallelExecutionErrors tests that all branches run and failures are keyed by target state
+func TestParallelExecutionErrors(t *testing.T) {
+	sm := buildConcurrentMachine(t, "ParallelErrorsTest", ParallelOptions{},
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			if tc.Transition.Target == StateB {
+				return nil
+			}
+			return errors.New("service unavailable")
+		})
+
+	targets, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+	var parallelErr *ParallelError[testState]
+	if !errors.As(err, &parallelErr) {
+		t.Fatalf("Expected *ParallelError, got %v", err)
+	}
+	if len(parallelErr.Errors) != 2 || parallelErr.Errors[StateC] == nil || parallelErr.Errors[StateD] == nil {
+		t.Errorf("Expected errors for C and D, got %v", parallelErr.Errors)
+	}
+	if !errors.Is(err, ErrActionExecutionFailed) {
+		t.Error("Expected ParallelError to match ErrActionExecutionFailed")
+	}
+	if len(targets) != 1 || targets[0] != StateB {
+		t.Errorf("Expected completed branch [B], got %v", targets)
+	}
+	if err.Error() != "parallel transition failed: C: action execution failed in ParallelErrorsTest: A --Event1--> C: service unavailable; "+
+		"D: action execution failed in ParallelErrorsTest: A --Event1--> D: service unavailable" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+// TestParallelExecutionFailFast tests that a failing branch cancels the others
+func TestParallelExecutionFailFast(t *testing.T) {
+	sm := buildConcurrentMachine(t, "ParallelFailFastTest", ParallelOptions{FailFast: true},
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			if tc.Transition.Target == StateB {
+				return errors.New("service unavailable")
+			}
+			select {
+			case <-tc.Done():
+				return tc.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+
+	start := time.Now()
+	_, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+	if time.Since(start) > time.Second {
+		t.Error("Expected the other branches to be cancelled")
+	}
+
+	var parallelErr *ParallelError[testState]
+	if !errors.As(err, &parallelErr) || len(parallelErr.Errors) != 3 {
+		t.Fatalf("Expected all three branches to fail, got %v", err)
+	}
+}
+
+// TestParallelExecutionContextCancelled tests that branches waiting for a slot are skipped when the caller cancels
+func TestParallelExecutionContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithParallelExecution(ParallelOptions{MaxConcurrency: 1})
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				atomic.AddInt32(&calls, 1)
+				cancel()
+				return nil
+			}))
+
+	sm, err := builder.Build("ParallelCancelledTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	impl := sm.(*StateMachineImpl[testState, testEvent, testPayload])
+
+	targets, err := impl.fire(ctx, StateA, Event1, testPayload{}, true, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the waiting branch to be cancelled, got %v", err)
+	}
+	if calls != 1 || len(targets) != 1 {
+		t.Errorf("Expected exactly one branch to run, got %d calls and targets %v", calls, targets)
+	}
+}
+
+// TestParallelExecutionPanicReachesCaller tests that a branch panic without panic recovery can be recovered by the caller
+func TestParallelExecutionPanicReachesCaller(t *testing.T) {
+	sm := buildConcurrentMachine(t, "ParallelBranchPanicTest", ParallelOptions{},
+		func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+			if tc.Transition.Target == StateC {
+				panic("branch exploded")
+			}
+			return nil
+		})
+
+	recovered := func() (value interface{}) {
+		defer func() {
+			value = recover()
+		}()
+		sm.FireParallelEvent(StateA, Event1, testPayload{})
+		return nil
+	}()
+	if recovered != "branch exploded" {
+		t.Errorf("Expected the branch panic on the calling goroutine, got %v", recovered)
+	}
+}
+
+// TestParallelSequentialPartialFailure tests that a failed sequential firing returns the completed branches with the error
+func TestParallelSequentialPartialFailure(t *testing.T) {
+	var executed []testState
+	unavailable := errors.New("service unavailable")
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC, StateD).
+		On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			executed = append(executed, to)
+			if to == StateC {
+				return unavailable
+			}
+			return nil
+		})
+
+	sm, err := builder.Build("ParallelSequentialPartialFailureTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	targets, err := sm.FireParallelEvent(StateA, Event1, testPayload{})
+	if !errors.Is(err, unavailable) {
+		t.Errorf("Expected the error of branch C, got %v", err)
+	}
+	if !reflect.DeepEqual(targets, []testState{StateB}) {
+		t.Errorf("Expected the completed branch [B] with the error, got %v", targets)
+	}
+	if !reflect.DeepEqual(executed, []testState{StateB, StateC}) {
+		t.Errorf("Expected the firing to stop at the failed branch, got %v", executed)
+	}
+}
@@ -0,0 +1,30 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sortByPriority orders the transitions of an event by descending priority, keeping declaration order among equals
+func (s *State[S, E, P]) sortByPriority(event E) {
+	transitions := s.eventTransitions[event]
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if transitions[i].Priority != transitions[j].Priority {
+			return transitions[i].Priority > transitions[j].Priority
+		}
+		return transitions[i].sequence < transitions[j].sequence
+	})
+}
+
+// contends reports whether a satisfied candidate evaluated after the winner makes a single firing ambiguous
+// Candidates are ordered by priority, so only strict mode and an equal priority make them contend
+// Branches of parallel transitions are meant to be satisfied together and never contend
+func (sm *StateMachineImpl[S, E, P]) contends(winner, candidate *Transition[S, E, P]) bool {
+	return sm.strict && candidate.Priority == winner.Priority && !winner.parallel && !candidate.parallel
+}
+
+// ambiguityError reports two satisfied candidates of the same priority
+func ambiguityError[S comparable, E comparable, P any](winner, candidate *Transition[S, E, P]) error {
+	return fmt.Errorf("%w: %v --%v--> %v and %v with priority %d",
+		ErrAmbiguousTransition, winner.Source.GetID(), winner.Event, winner.Target.GetID(), candidate.Target.GetID(), winner.Priority)
+}
@@ -0,0 +1,159 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestTransitionPriority tests that candidates are evaluated by priority and in declaration order among equals
+func TestTransitionPriority(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateC).On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value == "c"
+		}).
+		Perform(&noopAction{}).
+		Priority(10)
+	builder.ExternalTransition().From(StateA).To(StateD).On(Event1).Perform(&noopAction{}).Priority(10)
+
+	sm, err := builder.Build("TransitionPriorityTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{Value: "c"}); err != nil || state != StateC {
+		t.Errorf("Expected the first transition of the highest priority, got %v %v", state, err)
+	}
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateD {
+		t.Errorf("Expected the next transition of the highest priority, got %v %v", state, err)
+	}
+
+	var targets []testState
+	for _, info := range sm.Transitions(StateA) {
+		targets = append(targets, info.Target)
+	}
+	if !reflect.DeepEqual(targets, []testState{StateC, StateD, StateB}) {
+		t.Errorf("Expected descriptors in evaluation order, got %v", targets)
+	}
+}
+
+// TestStrictMode tests that strict mode rejects ambiguous single firings consistently with Explain and Simulate
+func TestStrictMode(t *testing.T) {
+	isVIP := func(payload testPayload) bool {
+		return payload.Value == "vip"
+	}
+	isLarge := func(payload testPayload) bool {
+		return payload.Value == "vip" || payload.Value == "large"
+	}
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithStrictMode()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).WhenFunc(isVIP).Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateC).On(Event1).WhenFunc(isLarge).Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateD).On(Event1).Perform(&noopAction{}).Priority(-1)
+	builder.ExternalTransition().From(StateB).To(StateC).On(Event2).WhenFunc(isVIP).Perform(&noopAction{}).Priority(1)
+	builder.ExternalTransition().From(StateB).To(StateD).On(Event2).WhenFunc(isLarge).Perform(&noopAction{})
+
+	sm, err := builder.Build("StrictModeTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	vip := testPayload{Value: "vip"}
+	if _, err := sm.FireEvent(StateA, Event1, vip); !errors.Is(err, ErrAmbiguousTransition) {
+		t.Errorf("Expected ErrAmbiguousTransition, got %v", err)
+	}
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{Value: "large"}); err != nil || state != StateC {
+		t.Errorf("Expected a single satisfied transition to be taken, got %v %v", state, err)
+	}
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateD {
+		t.Errorf("Expected lower priorities to be taken when nothing else is satisfied, got %v %v", state, err)
+	}
+	if state, err := sm.FireEvent(StateB, Event2, vip); err != nil || state != StateC {
+		t.Errorf("Expected the priority to resolve the ambiguity, got %v %v", state, err)
+	}
+	if targets, err := sm.FireParallelEvent(StateA, Event1, vip); err != nil || len(targets) != 3 {
+		t.Errorf("Expected parallel firings to be unaffected, got %v %v", targets, err)
+	}
+
+	explanation := sm.Explain(StateA, Event1, vip)
+	if !errors.Is(explanation.Err, ErrAmbiguousTransition) || explanation.Winner != nil {
+		t.Errorf("Expected Explain to report the ambiguity, got %v %v", explanation.Winner, explanation.Err)
+	}
+
+	result, err := sm.Simulate(StateA, Event1, vip)
+	if !errors.Is(err, ErrAmbiguousTransition) {
+		t.Errorf("Expected Simulate to report the ambiguity, got %v", err)
+	}
+	var reasons []string
+	for _, evaluation := range result.Considered {
+		if evaluation.Selected {
+			t.Errorf("Expected no candidate to be selected, got %v", evaluation.Transition.Target)
+		}
+		reasons = append(reasons, evaluation.Reason)
+	}
+	if expected := []string{ReasonAmbiguous, ReasonAmbiguous, ReasonPrecededByEarlier}; !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("Expected reasons %v, got %v", expected, reasons)
+	}
+}
+
+// TestTransitionPriorityDeclaredFirst tests that a lower priority declared first does not win over a later default one
+func TestTransitionPriorityDeclaredFirst(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithStrictMode()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).Perform(&noopAction{}).Priority(-1)
+	builder.ExternalTransition().From(StateA).To(StateC).On(Event1).Perform(&noopAction{})
+	builder.ExternalTransition().From(StateA).To(StateD).On(Event1).Perform(&noopAction{}).Priority(-1)
+
+	sm, err := builder.Build("TransitionPriorityDeclaredFirstTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateC {
+		t.Errorf("Expected the default priority to win, got %v %v", state, err)
+	}
+
+	var targets []testState
+	for _, info := range sm.Transitions(StateA) {
+		targets = append(targets, info.Target)
+	}
+	if !reflect.DeepEqual(targets, []testState{StateC, StateB, StateD}) {
+		t.Errorf("Expected descriptors in evaluation order, got %v", targets)
+	}
+}
+
+// TestStrictModeParallelTransition tests that the branches of a parallel transition do not make single firings ambiguous
+func TestStrictModeParallelTransition(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithStrictMode()
+	builder.ExternalParallelTransition().From(StateA).ToAmong(StateB, StateC).On(Event1).Perform(&noopAction{})
+
+	sm, err := builder.Build("StrictModeParallelTransitionTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected the first branch to be taken, got %v %v", state, err)
+	}
+	if targets, err := sm.FireParallelEvent(StateA, Event1, testPayload{}); err != nil || len(targets) != 2 {
+		t.Errorf("Expected every branch to be taken in parallel, got %v %v", targets, err)
+	}
+
+	explanation := sm.Explain(StateA, Event1, testPayload{})
+	if explanation.Err != nil || explanation.Winner == nil || explanation.Winner.Target != StateB {
+		t.Errorf("Expected Explain to select the first branch, got %v %v", explanation.Winner, explanation.Err)
+	}
+
+	result, err := sm.Simulate(StateA, Event1, testPayload{})
+	if err != nil || result.Target != StateB {
+		t.Fatalf("Expected Simulate to select the first branch, got %v %v", result.Target, err)
+	}
+	var reasons []string
+	for _, evaluation := range result.Considered {
+		reasons = append(reasons, evaluation.Reason)
+	}
+	if expected := []string{ReasonSelected, ReasonPrecededByEarlier}; !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("Expected reasons %v, got %v", expected, reasons)
+	}
+}
@@ -0,0 +1,107 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// createQueryStateMachine creates a machine with guarded, internal and multi-source transitions
+func createQueryStateMachine(t *testing.T, machineId string) StateMachine[testState, testEvent, testPayload] {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value == "ok"
+		}).
+		Perform(&noopAction{})
+
+	builder.InternalTransition().
+		Within(StateA).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	builder.ExternalTransitions().
+		FromAmong(StateA, StateB).
+		To(StateC).
+		On(Event3).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build(machineId)
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	return sm
+}
+
+// TestAvailableEvents tests event listing with and without condition evaluation
+func TestAvailableEvents(t *testing.T) {
+	sm := createQueryStateMachine(t, "QueryAvailableEvents")
+
+	if events := sm.AvailableEvents(StateA); !reflect.DeepEqual(events, []testEvent{Event1, Event2, Event3}) {
+		t.Errorf("Unexpected available events: %v", events)
+	}
+	if events := sm.AvailableEventsFor(StateA, testPayload{}); !reflect.DeepEqual(events, []testEvent{Event2, Event3}) {
+		t.Errorf("Unexpected available events for failing guard: %v", events)
+	}
+	if events := sm.AvailableEventsFor(StateA, testPayload{Value: "ok"}); !reflect.DeepEqual(events, []testEvent{Event1, Event2, Event3}) {
+		t.Errorf("Unexpected available events for passing guard: %v", events)
+	}
+	if events := sm.AvailableEvents(StateD); events != nil {
+		t.Errorf("Expected no events for unknown state, got %v", events)
+	}
+}
+
+// TestTransitionsStatesAndEvents tests the transition descriptors and machine-wide listings
+func TestTransitionsStatesAndEvents(t *testing.T) {
+	sm := createQueryStateMachine(t, "QueryTransitions")
+
+	transitions := sm.Transitions(StateA)
+	if len(transitions) != 3 {
+		t.Fatalf("Expected 3 transitions, got %d", len(transitions))
+	}
+
+	internal := transitions[1]
+	if internal.Type != Internal || internal.Source != StateA || internal.Target != StateA || internal.Event != Event2 {
+		t.Errorf("Unexpected internal transition descriptor: %+v", internal)
+	}
+	if !transitions[0].HasCondition || !transitions[0].HasAction {
+		t.Errorf("Expected condition and action to be reported: %+v", transitions[0])
+	}
+
+	if states := sm.States(); !reflect.DeepEqual(states, []testState{StateA, StateB, StateC}) {
+		t.Errorf("Unexpected states: %v", states)
+	}
+	if events := sm.Events(); !reflect.DeepEqual(events, []testEvent{Event1, Event2, Event3}) {
+		t.Errorf("Unexpected events: %v", events)
+	}
+}
+
+// TestTransitionInfoMetadataCopy tests that changing the metadata of a descriptor leaves the transition unchanged
+func TestTransitionInfoMetadataCopy(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				tc.Transition.Metadata["owner"] = "action"
+				return nil
+			})).
+		Metadata("owner", "ops")
+
+	sm, err := builder.Build("TransitionInfoMetadataCopyTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	sm.Transitions(StateA)[0].Metadata["owner"] = "caller"
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	if owner := sm.Transitions(StateA)[0].Metadata["owner"]; owner != "ops" {
+		t.Errorf("Expected the transition metadata to be unchanged, got %q", owner)
+	}
+}
@@ -0,0 +1,108 @@
+package fsm
+
+import (
+	"sync"
+)
+
+// Registry binds conditions and actions to names so that state machines
+// described outside of Go code (diagrams, definition files) can refer to them
+type Registry[S comparable, E comparable, P any] struct {
+	conditions map[string]Condition[P]
+	actions    map[string]Action[S, E, P]
+	mutex      sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+// Returns:
+//
+//	A new registry instance
+func NewRegistry[S comparable, E comparable, P any]() *Registry[S, E, P] {
+	return &Registry[S, E, P]{
+		conditions: make(map[string]Condition[P]),
+		actions:    make(map[string]Action[S, E, P]),
+	}
+}
+
+// RegisterCondition binds a condition to a name, replacing any previous binding
+// Parameters:
+//
+//	name: Name used to refer to the condition
+//	condition: The condition to bind
+//
+// Returns:
+//
+//	The registry for method chaining
+func (r *Registry[S, E, P]) RegisterCondition(name string, condition Condition[P]) *Registry[S, E, P] {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.conditions[name] = condition
+	return r
+}
+
+// RegisterConditionFunc binds a condition function to a name
+// Parameters:
+//
+//	name: Name used to refer to the condition
+//	conditionFunc: The function to bind
+//
+// Returns:
+//
+//	The registry for method chaining
+func (r *Registry[S, E, P]) RegisterConditionFunc(name string, conditionFunc func(payload P) bool) *Registry[S, E, P] {
+	return r.RegisterCondition(name, ConditionFunc[P](conditionFunc))
+}
+
+// RegisterAction binds an action to a name, replacing any previous binding
+// Parameters:
+//
+//	name: Name used to refer to the action
+//	action: The action to bind
+//
+// Returns:
+//
+//	The registry for method chaining
+func (r *Registry[S, E, P]) RegisterAction(name string, action Action[S, E, P]) *Registry[S, E, P] {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.actions[name] = action
+	return r
+}
+
+// RegisterActionFunc binds an action function to a name
+// Parameters:
+//
+//	name: Name used to refer to the action
+//	actionFunc: The function to bind
+//
+// Returns:
+//
+//	The registry for method chaining
+func (r *Registry[S, E, P]) RegisterActionFunc(name string, actionFunc func(from, to S, event E, payload P) error) *Registry[S, E, P] {
+	return r.RegisterAction(name, ActionFunc[S, E, P](actionFunc))
+}
+
+// Condition looks up a condition by name
+// Returns:
+//
+//	The condition and true if found, nil and false otherwise
+func (r *Registry[S, E, P]) Condition(name string) (Condition[P], bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	condition, ok := r.conditions[name]
+	return condition, ok
+}
+
+// Action looks up an action by name
+// Returns:
+//
+//	The action and true if found, nil and false otherwise
+func (r *Registry[S, E, P]) Action(name string) (Action[S, E, P], bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	action, ok := r.actions[name]
+	return action, ok
+}
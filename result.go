@@ -0,0 +1,85 @@
+package fsm
+
+import (
+	"context"
+	"time"
+)
+
+// Result describes what happened while firing an event
+type Result[S comparable, E comparable] struct {
+	// Source is the state the event was fired from
+	Source S
+	// Event is the fired event
+	Event E
+	// Target is the state the machine moved to, the first target for a parallel firing
+	Target S
+	// Targets are all states the machine moved to, in execution order
+	Targets []S
+	// Transitions describe the executed transitions, in the same order as Targets
+	Transitions []TransitionInfo[S, E]
+	// Internal reports whether every executed transition was an internal transition
+	Internal bool
+	// GuardEvaluations is the number of conditions evaluated to select the transitions
+	GuardEvaluations int
+	// ActionDurations holds how long the action of each executed transition took, zero without an action
+	ActionDurations []time.Duration
+	// Raised holds the follow-up events raised while executing the transitions, in order
+	Raised []E
+	// Ignored reports whether no transition was declared for the event and the machine stayed in the source state,
+	// see UnhandledEventPolicy
+	Ignored bool
+}
+
+// Fire triggers a state transition like FireEvent and reports the details of the firing
+// Parameters:
+//
+//	sourceStateId: The state to fire from
+//	event: The event to fire
+//	payload: The payload passed to conditions and actions
+//
+// Returns:
+//
+//	The result, filled as far as the firing got, and any error that occurred
+func (sm *StateMachineImpl[S, E, P]) Fire(sourceStateId S, event E, payload P) (Result[S, E], error) {
+	result := &Result[S, E]{Source: sourceStateId, Event: event}
+
+	// The middleware is composed around a core filling this result, so it is filled whatever context
+	// the middleware passes on; a middleware calling next more than once leaves the result of the last call
+	sm.mutex.RLock()
+	fire := composeMiddleware(sm.middleware, func(ctx context.Context, sourceStateId S, event E, payload P) (S, error) {
+		*result = Result[S, E]{Source: sourceStateId, Event: event}
+		return sm.fireEvent(ctx, sourceStateId, event, payload, result)
+	})
+	sm.mutex.RUnlock()
+
+	_, err := fire(context.Background(), sourceStateId, event, payload)
+	return *result, err
+}
+
+// FireParallel triggers parallel state transitions like FireParallelEvent and reports the details of the firing
+// Parameters:
+//
+//	sourceStateId: The state to fire from
+//	event: The event to fire
+//	payload: The payload passed to conditions and actions
+//
+// Returns:
+//
+//	The result, filled as far as the firing got, and any error that occurred
+func (sm *StateMachineImpl[S, E, P]) FireParallel(sourceStateId S, event E, payload P) (Result[S, E], error) {
+	result := &Result[S, E]{Source: sourceStateId, Event: event}
+	_, err := sm.fire(context.Background(), sourceStateId, event, payload, true, result)
+	return *result, err
+}
+
+// record adds an executed transition to the result
+func (r *Result[S, E]) record(info TransitionInfo[S, E], duration time.Duration) {
+	if len(r.Targets) == 0 {
+		r.Target = info.Target
+		r.Internal = true
+	}
+	r.Targets = append(r.Targets, info.Target)
+	r.Transitions = append(r.Transitions, info)
+	r.ActionDurations = append(r.ActionDurations, duration)
+	r.Internal = r.Internal && info.Type == Internal
+}
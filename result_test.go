@@ -0,0 +1,117 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestFireResult tests that Fire reports the executed transition and the evaluated guards
+func TestFireResult(t *testing.T) {
+	sm := createQueryStateMachine(t, "FireResultTest")
+
+	result, err := sm.Fire(StateA, Event1, testPayload{Value: "ok"})
+	if err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	if result.Source != StateA || result.Event != Event1 || result.Target != StateB || result.Internal {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Transitions) != 1 || result.Transitions[0].Target != StateB || !result.Transitions[0].HasCondition {
+		t.Errorf("Unexpected transitions: %+v", result.Transitions)
+	}
+	if result.GuardEvaluations != 1 {
+		t.Errorf("Expected 1 guard evaluation, got %d", result.GuardEvaluations)
+	}
+	if len(result.ActionDurations) != 1 {
+		t.Errorf("Expected 1 action duration, got %v", result.ActionDurations)
+	}
+
+	result, err = sm.Fire(StateA, Event2, testPayload{})
+	if err != nil {
+		t.Fatalf("Failed to fire internal event: %v", err)
+	}
+	if !result.Internal || result.Target != StateA {
+		t.Errorf("Expected internal transition within A, got %+v", result)
+	}
+
+	result, err = sm.Fire(StateA, Event1, testPayload{Value: "no"})
+	if err != ErrConditionNotMet {
+		t.Errorf("Expected ErrConditionNotMet, got %v", err)
+	}
+	if result.GuardEvaluations != 1 || len(result.Transitions) != 0 {
+		t.Errorf("Expected one rejected guard and no transitions, got %+v", result)
+	}
+}
+
+// TestFireResultThroughMiddleware tests that the result is filled when Fire passes through middleware
+func TestFireResultThroughMiddleware(t *testing.T) {
+	sm := createQueryStateMachine(t, "FireResultMiddlewareTest")
+	sm.Use(func(next FireFunc[testState, testEvent, testPayload]) FireFunc[testState, testEvent, testPayload] {
+		return func(ctx context.Context, sourceState testState, event testEvent, payload testPayload) (testState, error) {
+			payload.Value = "ok"
+			return next(ctx, sourceState, event, payload)
+		}
+	})
+
+	result, err := sm.Fire(StateA, Event1, testPayload{})
+	if err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	if result.Target != StateB {
+		t.Errorf("Expected target B, got %v", result.Target)
+	}
+}
+
+// TestFireResultWithReplacedContext tests that the result is filled when middleware passes a new context to next
+func TestFireResultWithReplacedContext(t *testing.T) {
+	sm := createQueryStateMachine(t, "FireResultReplacedContextTest")
+	sm.Use(func(next FireFunc[testState, testEvent, testPayload]) FireFunc[testState, testEvent, testPayload] {
+		return func(ctx context.Context, sourceState testState, event testEvent, payload testPayload) (testState, error) {
+			return next(context.Background(), sourceState, event, payload)
+		}
+	})
+
+	result, err := sm.Fire(StateA, Event1, testPayload{Value: "ok"})
+	if err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	if result.Target != StateB || len(result.Transitions) != 1 || result.GuardEvaluations != 1 {
+		t.Errorf("Expected the result of the firing, got %+v", result)
+	}
+}
+
+// TestFireParallelResult tests that FireParallel reports every branch
+func TestFireParallelResult(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateB, StateC).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+
+	sm, err := builder.Build("FireParallelResultTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	result, err := sm.FireParallel(StateA, Event1, testPayload{})
+	if err != nil {
+		t.Fatalf("Failed to fire parallel event: %v", err)
+	}
+	if len(result.Targets) != 2 || result.Targets[0] != StateB || result.Targets[1] != StateC || result.Target != StateB {
+		t.Errorf("Unexpected targets: %v", result.Targets)
+	}
+	if result.GuardEvaluations != 2 {
+		t.Errorf("Expected 2 guard evaluations, got %d", result.GuardEvaluations)
+	}
+	for i, duration := range result.ActionDurations {
+		if duration < time.Millisecond {
+			t.Errorf("Expected action %d to take at least 1ms, got %v", i, duration)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package fsm
+
+import (
+	"time"
+)
+
+// RetryPolicy retries a failing action before the firing reports the failure
+// An attempt that exceeded its timeout is waited for before it is retried, so attempts never overlap;
+// a plain action that completes after its timeout is run again, retrying timeouts suits idempotent actions
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one, values below 2 disable retries
+	MaxAttempts int
+	// Backoff returns the delay before the attempt following the given failed attempt, nil retries immediately
+	Backoff func(attempt int) time.Duration
+	// RetryIf decides whether an error is worth retrying, nil retries every error
+	RetryIf func(err error) bool
+}
+
+// ConstantBackoff waits the same delay before every retry
+// Parameters:
+//
+//	delay: The delay between attempts
+//
+// Returns:
+//
+//	A backoff function for RetryPolicy
+func ConstantBackoff(delay time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff doubles the delay after every failed attempt
+// Parameters:
+//
+//	initial: The delay after the first failed attempt
+//	max: The upper bound of the delay, no bound if zero
+//
+// Returns:
+//
+//	A backoff function for RetryPolicy
+func ExponentialBackoff(initial, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if max > 0 && delay >= max {
+				return max
+			}
+		}
+		if max > 0 && delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+// shouldRetry reports whether another attempt follows the given failed attempt
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.RetryIf == nil || p.RetryIf(err)
+}
+
+// executeWithRetry runs the action of a transition, retrying it as its retry policy allows
+// Retries of a chain resume at the action that failed. An attempt abandoned by its timeout is waited for
+// before the next one, so that the action never runs twice at the same time. Waiting between attempts uses
+// the machine's clock and stops early when the firing's context is done
+// It returns the number of attempts made, the progress through the chain and the error of the last attempt
+func (sm *StateMachineImpl[S, E, P]) executeWithRetry(f *firing[S, E], transition *Transition[S, E, P], payload P) (int, *actionRun, error) {
+	run := &actionRun{}
+	for attempt := 1; ; attempt++ {
+		err := sm.runAttempt(f, transition, payload, attempt, run)
+		if err == nil || !transition.Retry.shouldRetry(attempt, err) {
+			return attempt, run, err
+		}
+
+		if run.abandoned != nil {
+			select {
+			case outcome := <-run.abandoned:
+				outcome.panicked.raise()
+			case <-f.ctx.Done():
+				return attempt, run, err
+			}
+		}
+
+		if transition.Retry.Backoff != nil {
+			if delay := transition.Retry.Backoff(attempt); delay > 0 {
+				select {
+				case <-sm.currentClock().After(delay):
+				case <-f.ctx.Done():
+					return attempt, run, err
+				}
+			}
+		}
+	}
+}
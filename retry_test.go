@@ -0,0 +1,152 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingClock is a Clock that returns immediately from After and records the requested delays
+type recordingClock struct {
+	delays []time.Duration
+}
+
+func (c *recordingClock) Now() time.Time {
+	return time.Unix(0, 0)
+}
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now().Add(d)
+	return ch
+}
+
+var errTransient = errors.New("gateway timeout")
+
+// TestRetry tests that a failing action is retried with backoff and sees its attempt number
+func TestRetry(t *testing.T) {
+	clock := &recordingClock{}
+	var attempts []int
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithClock(clock)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				attempts = append(attempts, tc.Attempt)
+				if tc.Attempt < 3 {
+					return errTransient
+				}
+				return nil
+			})).
+		Retry(RetryPolicy{MaxAttempts: 5, Backoff: ExponentialBackoff(10*time.Millisecond, time.Second)})
+
+	sm, err := builder.Build("RetryTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	target, err := sm.FireEvent(StateA, Event1, testPayload{})
+	if err != nil || target != StateB {
+		t.Fatalf("Expected B after retries, got %v: %v", target, err)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("Expected attempts [1 2 3], got %v", attempts)
+	}
+	if len(clock.delays) != 2 || clock.delays[0] != 10*time.Millisecond || clock.delays[1] != 20*time.Millisecond {
+		t.Errorf("Expected delays [10ms 20ms], got %v", clock.delays)
+	}
+}
+
+// TestRetryExhausted tests that the failure is reported once all attempts failed or the error is not retryable
+func TestRetryExhausted(t *testing.T) {
+	calls := 0
+	permanent := errors.New("card declined")
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			calls++
+			if payload.Value == "permanent" {
+				return permanent
+			}
+			return errTransient
+		}).
+		Retry(RetryPolicy{
+			MaxAttempts: 3,
+			RetryIf: func(err error) bool {
+				return errors.Is(err, errTransient)
+			},
+		})
+
+	sm, err := builder.Build("RetryExhaustedTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected ErrActionExecutionFailed, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+
+	calls = 0
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{Value: "permanent"}); err == nil {
+		t.Error("Expected failure")
+	}
+	if calls != 1 {
+		t.Errorf("Expected a permanent error not to be retried, got %d attempts", calls)
+	}
+}
+
+// TestRetryStopsOnCancel tests that waiting between attempts ends when the context is cancelled
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			calls++
+			cancel()
+			return errTransient
+		}).
+		Retry(RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Hour)})
+
+	sm, err := builder.Build("RetryCancelTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEventContext(ctx, StateA, Event1, testPayload{}); err == nil {
+		t.Error("Expected failure")
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d attempts", calls)
+	}
+}
+
+// TestExponentialBackoff tests that the delay doubles up to the bound
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := backoff(i + 1); got != delay {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, delay, got)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Package saga runs business processes declared as ordered steps with compensating actions
+// The steps are compiled into an fsm-go state machine: every step has a state in which it is about to run
+// and a state in which it is about to be compensated, plus the Completed and Failed final states
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lingcoder/fsm-go"
+)
+
+// State is a state of the compiled saga machine
+type State string
+
+// Final states of a saga
+const (
+	// Completed means every step succeeded
+	Completed State = "completed"
+	// Failed means a step failed and the steps before it were compensated
+	Failed State = "failed"
+)
+
+// Event is an event of the compiled saga machine
+type Event string
+
+const (
+	// EventNext runs the step of the current state and advances to the next one
+	EventNext Event = "next"
+	// EventFail starts compensating after the step of the current state failed
+	EventFail Event = "fail"
+	// EventCompensate runs the compensation of the current state and moves to the previous step
+	EventCompensate Event = "compensate"
+)
+
+// Errors returned when declaring a saga
+var (
+	ErrNoSteps           = errors.New("saga has no steps")
+	ErrInvalidStepName   = errors.New("saga step name must not be empty")
+	ErrDuplicateStepName = errors.New("saga step name is used twice")
+	ErrMissingAction     = errors.New("saga step has no action")
+)
+
+// StepState is the state in which the named step is about to run
+func StepState(name string) State {
+	return State("step:" + name)
+}
+
+// CompensationState is the state in which the named step is about to be compensated
+func CompensationState(name string) State {
+	return State("compensate:" + name)
+}
+
+// Step is one unit of work of a saga
+type Step[T any] struct {
+	// Name identifies the step, it must be unique within the saga
+	Name string
+	// Action performs the step
+	Action func(ctx context.Context, data T) error
+	// Compensate undoes the step after a later step failed, optional
+	Compensate func(ctx context.Context, data T) error
+}
+
+// Execution is the payload of the compiled machine, one per saga run
+type Execution[T any] struct {
+	// ID identifies the run, progress is stored under it
+	ID string
+	// Data is passed to every step
+	Data T
+
+	ctx context.Context
+	err error // error returned by the last step action or compensation
+}
+
+// Context returns the context of the run
+func (e *Execution[T]) Context() context.Context {
+	return e.ctx
+}
+
+// StepError reports the step whose action or compensation failed
+type StepError struct {
+	// Step is the name of the failed step
+	Step string
+	// Compensating is set when the compensation failed, the run can then be resumed to retry it
+	Compensating bool
+	// Err is the failure
+	Err error
+}
+
+// Error implements error interface
+func (e *StepError) Error() string {
+	if e.Compensating {
+		return fmt.Sprintf("saga compensation of step %q failed: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("saga step %q failed: %v", e.Step, e.Err)
+}
+
+// Unwrap returns the failure
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Saga is a compiled saga definition
+type Saga[T any] struct {
+	id      string
+	steps   []Step[T]
+	store   Store
+	machine fsm.StateMachine[State, Event, *Execution[T]]
+}
+
+// New compiles ordered steps into a saga
+// The state machine is registered under the saga id, so the id must be unique like any state machine id
+// Parameters:
+//
+//	id: Unique identifier of the saga
+//	store: Where progress is persisted, an in-memory store if nil
+//	steps: The steps in execution order
+//
+// Returns:
+//
+//	The saga and possible error
+func New[T any](id string, store Store, steps ...Step[T]) (*Saga[T], error) {
+	if len(steps) == 0 {
+		return nil, ErrNoSteps
+	}
+
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		switch {
+		case step.Name == "":
+			return nil, ErrInvalidStepName
+		case seen[step.Name]:
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateStepName, step.Name)
+		case step.Action == nil:
+			return nil, fmt.Errorf("%w: %s", ErrMissingAction, step.Name)
+		}
+		seen[step.Name] = true
+	}
+
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	machine, err := compile(id, steps)
+	if err != nil {
+		return nil, err
+	}
+	return &Saga[T]{id: id, steps: steps, store: store, machine: machine}, nil
+}
+
+// compile builds the state machine of a saga
+func compile[T any](id string, steps []Step[T]) (fsm.StateMachine[State, Event, *Execution[T]], error) {
+	builder := fsm.NewStateMachineBuilder[State, Event, *Execution[T]]()
+	always := fsm.ConditionFunc[*Execution[T]](func(*Execution[T]) bool { return true })
+	noop := func(from, to State, event Event, execution *Execution[T]) error { return nil }
+
+	for i, step := range steps {
+		next, previous := Completed, Failed
+		if i+1 < len(steps) {
+			next = StepState(steps[i+1].Name)
+		}
+		if i > 0 {
+			previous = CompensationState(steps[i-1].Name)
+		}
+
+		builder.ExternalTransition().
+			From(StepState(step.Name)).
+			To(next).
+			On(EventNext).
+			When(always).
+			PerformFunc(run(step.Action))
+		builder.ExternalTransition().
+			From(StepState(step.Name)).
+			To(previous).
+			On(EventFail).
+			When(always).
+			PerformFunc(noop)
+
+		compensation := noop
+		if step.Compensate != nil {
+			compensation = run(step.Compensate)
+		}
+		builder.ExternalTransition().
+			From(CompensationState(step.Name)).
+			To(previous).
+			On(EventCompensate).
+			When(always).
+			PerformFunc(compensation)
+	}
+
+	return builder.Build(id)
+}
+
+// run adapts a step function to an action, keeping its error on the execution
+func run[T any](fn func(ctx context.Context, data T) error) func(from, to State, event Event, execution *Execution[T]) error {
+	return func(from, to State, event Event, execution *Execution[T]) error {
+		execution.err = fn(execution.ctx, execution.Data)
+		return execution.err
+	}
+}
+
+// Machine returns the compiled state machine, for example to render it with GenerateDiagram
+func (s *Saga[T]) Machine() fsm.StateMachine[State, Event, *Execution[T]] {
+	return s.machine
+}
+
+// Run executes a saga run, resuming from the stored progress if the run was started before
+// Parameters:
+//
+//	ctx: Passed to every step
+//	executionId: Identifies the run in the store
+//	data: Passed to every step
+//
+// Returns:
+//
+//	Completed and nil when every step succeeded, Failed and a *StepError when a step failed and the
+//	earlier steps were compensated, or the current state and an error when the run could not go on
+func (s *Saga[T]) Run(ctx context.Context, executionId string, data T) (State, error) {
+	progress, found, err := s.store.Load(ctx, executionId)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		progress = Progress{State: StepState(s.steps[0].Name)}
+	}
+
+	execution := &Execution[T]{ID: executionId, Data: data, ctx: ctx}
+	for {
+		switch progress.State {
+		case Completed:
+			return Completed, nil
+		case Failed:
+			return Failed, progress.failure()
+		}
+
+		if err := ctx.Err(); err != nil {
+			return progress.State, err
+		}
+
+		step, compensating := s.stepOf(progress.State)
+		if step == "" {
+			return progress.State, fmt.Errorf("saga %s: unknown state %q", s.id, progress.State)
+		}
+
+		if compensating {
+			next, err := s.machine.FireEvent(progress.State, EventCompensate, execution)
+			if err != nil {
+				return progress.State, &StepError{Step: step, Compensating: true, Err: execution.cause(err)}
+			}
+			progress.State = next
+		} else if next, err := s.machine.FireEvent(progress.State, EventNext, execution); err == nil {
+			progress.State = next
+		} else {
+			progress.FailedStep, progress.Error = step, execution.cause(err).Error()
+			if progress.State, err = s.machine.FireEvent(progress.State, EventFail, execution); err != nil {
+				return StepState(step), err
+			}
+		}
+
+		if err := s.store.Save(ctx, executionId, progress); err != nil {
+			return progress.State, err
+		}
+	}
+}
+
+// stepOf returns the step name of a step or compensation state
+func (s *Saga[T]) stepOf(state State) (name string, compensating bool) {
+	for _, step := range s.steps {
+		switch state {
+		case StepState(step.Name):
+			return step.Name, false
+		case CompensationState(step.Name):
+			return step.Name, true
+		}
+	}
+	return "", false
+}
+
+// cause returns the error of the step function, or err when the firing failed before reaching it
+func (e *Execution[T]) cause(err error) error {
+	if e.err != nil {
+		cause := e.err
+		e.err = nil
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// order is the data passed through the fulfillment saga in tests
+type order struct {
+	log      *[]string
+	chargeOK bool
+}
+
+// fulfillmentSteps returns reserve, charge and ship steps that record their calls
+func fulfillmentSteps() []Step[order] {
+	record := func(name string) func(ctx context.Context, data order) error {
+		return func(ctx context.Context, data order) error {
+			*data.log = append(*data.log, name)
+			return nil
+		}
+	}
+	return []Step[order]{
+		{Name: "reserve", Action: record("reserve"), Compensate: record("release")},
+		{Name: "notify", Action: record("notify")},
+		{
+			Name: "charge",
+			Action: func(ctx context.Context, data order) error {
+				*data.log = append(*data.log, "charge")
+				if !data.chargeOK {
+					return errors.New("card declined")
+				}
+				return nil
+			},
+			Compensate: record("refund"),
+		},
+		{Name: "ship", Action: record("ship")},
+	}
+}
+
+// TestSagaCompleted tests that every step runs in order
+func TestSagaCompleted(t *testing.T) {
+	saga, err := New("SagaCompletedTest", nil, fulfillmentSteps()...)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	var log []string
+	state, err := saga.Run(context.Background(), "order-1", order{log: &log, chargeOK: true})
+	if err != nil || state != Completed {
+		t.Fatalf("Expected completed saga, got %s: %v", state, err)
+	}
+	if !reflect.DeepEqual(log, []string{"reserve", "notify", "charge", "ship"}) {
+		t.Errorf("Unexpected step order: %v", log)
+	}
+}
+
+// TestSagaCompensated tests that the steps before a failing step are compensated in reverse order
+func TestSagaCompensated(t *testing.T) {
+	store := NewMemoryStore()
+	saga, err := New("SagaCompensatedTest", store, fulfillmentSteps()...)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	var log []string
+	state, err := saga.Run(context.Background(), "order-2", order{log: &log})
+	if state != Failed {
+		t.Fatalf("Expected failed saga, got %s", state)
+	}
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "charge" || stepErr.Compensating || stepErr.Err.Error() != "card declined" {
+		t.Errorf("Expected charge failure, got %v", err)
+	}
+	if !reflect.DeepEqual(log, []string{"reserve", "notify", "charge", "release"}) {
+		t.Errorf("Unexpected step order: %v", log)
+	}
+
+	// Running a finished saga again reports the stored outcome without executing anything
+	log = nil
+	state, err = saga.Run(context.Background(), "order-2", order{log: &log})
+	if state != Failed || err == nil || !strings.Contains(err.Error(), "card declined") || len(log) != 0 {
+		t.Errorf("Expected stored failure without new calls, got %s, %v, %v", state, err, log)
+	}
+}
+
+// TestSagaResume tests that a run resumes at the step recorded in the store
+func TestSagaResume(t *testing.T) {
+	store := NewMemoryStore()
+	saga, err := New("SagaResumeTest", store, fulfillmentSteps()...)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	// The process crashed after notify completed
+	if err := store.Save(context.Background(), "order-3", Progress{State: StepState("charge")}); err != nil {
+		t.Fatalf("Failed to save progress: %v", err)
+	}
+
+	var log []string
+	state, err := saga.Run(context.Background(), "order-3", order{log: &log, chargeOK: true})
+	if err != nil || state != Completed {
+		t.Fatalf("Expected completed saga, got %s: %v", state, err)
+	}
+	if !reflect.DeepEqual(log, []string{"charge", "ship"}) {
+		t.Errorf("Expected to resume at charge, got %v", log)
+	}
+
+	progress, found, _ := store.Load(context.Background(), "order-3")
+	if !found || progress.State != Completed {
+		t.Errorf("Expected completed progress, got %+v", progress)
+	}
+}
+
+// TestSagaCompensationFailure tests that a failing compensation stops the run so it can be resumed
+func TestSagaCompensationFailure(t *testing.T) {
+	attempts := 0
+	steps := fulfillmentSteps()
+	steps[0].Compensate = func(ctx context.Context, data order) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("warehouse offline")
+		}
+		*data.log = append(*data.log, "release")
+		return nil
+	}
+
+	saga, err := New("SagaCompensationFailureTest", nil, steps...)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	var log []string
+	state, err := saga.Run(context.Background(), "order-4", order{log: &log})
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || !stepErr.Compensating || stepErr.Step != "reserve" {
+		t.Fatalf("Expected compensation failure of reserve, got %v", err)
+	}
+	if state != CompensationState("reserve") {
+		t.Errorf("Expected to stop before compensating reserve, got %s", state)
+	}
+
+	state, err = saga.Run(context.Background(), "order-4", order{log: &log})
+	if state != Failed || !errors.As(err, &stepErr) || stepErr.Step != "charge" {
+		t.Errorf("Expected the resumed run to finish compensating, got %s: %v", state, err)
+	}
+	if log[len(log)-1] != "release" {
+		t.Errorf("Expected release to run on resume, got %v", log)
+	}
+}
+
+// TestSagaValidation tests that invalid step lists are rejected
+func TestSagaValidation(t *testing.T) {
+	action := func(ctx context.Context, data order) error { return nil }
+	testCases := []struct {
+		steps    []Step[order]
+		expected error
+	}{
+		{nil, ErrNoSteps},
+		{[]Step[order]{{Action: action}}, ErrInvalidStepName},
+		{[]Step[order]{{Name: "a", Action: action}, {Name: "a", Action: action}}, ErrDuplicateStepName},
+		{[]Step[order]{{Name: "a"}}, ErrMissingAction},
+	}
+	for i, tc := range testCases {
+		if _, err := New("SagaValidationTest", nil, tc.steps...); !errors.Is(err, tc.expected) {
+			t.Errorf("Case %d: expected %v, got %v", i, tc.expected, err)
+		}
+	}
+}
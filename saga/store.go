@@ -0,0 +1,61 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Progress is the persisted position of a saga run
+type Progress struct {
+	// State is the state the run is in
+	State State `json:"state"`
+	// FailedStep is the step whose failure started the compensation, empty while moving forward
+	FailedStep string `json:"failedStep,omitempty"`
+	// Error is the message of that failure
+	Error string `json:"error,omitempty"`
+}
+
+// failure rebuilds the error of a failed run
+func (p Progress) failure() error {
+	return &StepError{Step: p.FailedStep, Err: errors.New(p.Error)}
+}
+
+// Store persists the progress of saga runs
+// Save is called after every step, so a run interrupted by a crash resumes at the step it had reached
+type Store interface {
+	// Load returns the progress of a run and whether the run is known
+	Load(ctx context.Context, executionId string) (Progress, bool, error)
+
+	// Save records the progress of a run
+	Save(ctx context.Context, executionId string, progress Progress) error
+}
+
+// MemoryStore keeps progress in memory, it is safe for concurrent use
+type MemoryStore struct {
+	progress map[string]Progress
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{progress: make(map[string]Progress)}
+}
+
+// Load implements Store interface
+func (m *MemoryStore) Load(ctx context.Context, executionId string) (Progress, bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	progress, ok := m.progress[executionId]
+	return progress, ok, nil
+}
+
+// Save implements Store interface
+func (m *MemoryStore) Save(ctx context.Context, executionId string, progress Progress) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.progress[executionId] = progress
+	return nil
+}
@@ -0,0 +1,118 @@
+package fsm
+
+// SimulationResult describes what firing an event would do, without any action being executed
+type SimulationResult[S comparable, E comparable] struct {
+	// Source is the state the event was simulated from
+	Source S
+	// Event is the simulated event
+	Event E
+	// Target is the state FireEvent would move to
+	Target S
+	// Targets are the states FireParallelEvent would move to
+	Targets []S
+	// Considered lists every candidate transition in evaluation order, that is by priority
+	Considered []TransitionEvaluation[S, E]
+}
+
+// TransitionEvaluation records how a candidate transition was judged during a simulation
+type TransitionEvaluation[S comparable, E comparable] struct {
+	// Transition describes the candidate
+	Transition TransitionInfo[S, E]
+	// ConditionMet reports whether the candidate's condition passed (true when it has none)
+	ConditionMet bool
+	// Selected reports whether FireEvent would take this transition
+	Selected bool
+	// Reason explains why the candidate was selected or rejected
+	Reason string
+}
+
+// Reasons reported in simulation results
+const (
+	ReasonSelected          = "selected"
+	ReasonConditionNotMet   = "condition not satisfied"
+	ReasonPrecededByEarlier = "an earlier transition was selected"
+	ReasonAmbiguous         = "another transition of the same priority is satisfied"
+)
+
+// Simulate evaluates the conditions for an event and reports the transition(s) that would be taken
+// No action is executed, so the state machine and the payload are left untouched
+// Parameters:
+//
+//	sourceStateId: The state to simulate from
+//	event: The event to simulate
+//	payload: The payload conditions are evaluated against
+//
+// Returns:
+//
+//	The simulation result and the error FireEvent would return, if any
+func (sm *StateMachineImpl[S, E, P]) Simulate(sourceStateId S, event E, payload P) (SimulationResult[S, E], error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	result := SimulationResult[S, E]{
+		Source: sourceStateId,
+		Event:  event,
+	}
+
+	if !sm.ready {
+		return result, ErrStateMachineNotReady
+	}
+
+	sourceState, ok := sm.stateMap[sourceStateId]
+	if !ok {
+		return result, ErrStateNotFound
+	}
+
+	transitions := sourceState.GetEventTransitions(event)
+	if len(transitions) == 0 {
+		return result, ErrTransitionNotFound
+	}
+
+	var winner *Transition[S, E, P]
+	var ambiguity error
+	for _, transition := range transitions {
+		evaluation := TransitionEvaluation[S, E]{
+			Transition:   transition.Info(),
+			ConditionMet: transition.Condition == nil || sm.isSatisfied(nil, transition, payload),
+		}
+
+		switch {
+		case !evaluation.ConditionMet:
+			evaluation.Reason = ReasonConditionNotMet
+		case winner == nil:
+			winner = transition
+			evaluation.Selected = true
+			evaluation.Reason = ReasonSelected
+			result.Target = transition.Target.GetID()
+			result.Targets = append(result.Targets, transition.Target.GetID())
+		case sm.contends(winner, transition):
+			if ambiguity == nil {
+				ambiguity = ambiguityError(winner, transition)
+			}
+			evaluation.Reason = ReasonAmbiguous
+			result.Targets = append(result.Targets, transition.Target.GetID())
+		default:
+			evaluation.Reason = ReasonPrecededByEarlier
+			result.Targets = append(result.Targets, transition.Target.GetID())
+		}
+
+		result.Considered = append(result.Considered, evaluation)
+	}
+
+	if winner == nil {
+		return result, ErrConditionNotMet
+	}
+	if ambiguity != nil {
+		// FireEvent would take no transition, the first satisfied candidate is ambiguous as well
+		var zero S
+		result.Target = zero
+		for i := range result.Considered {
+			if result.Considered[i].Selected {
+				result.Considered[i].Selected = false
+				result.Considered[i].Reason = ReasonAmbiguous
+			}
+		}
+		return result, ambiguity
+	}
+	return result, nil
+}
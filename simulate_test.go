@@ -0,0 +1,79 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSimulate tests that simulation reports the chosen target and rejected candidates without running actions
+func TestSimulate(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	executed := 0
+	countingAction := func(from, to testState, event testEvent, payload testPayload) error {
+		executed++
+		return nil
+	}
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value == "b"
+		}).
+		PerformFunc(countingAction)
+	builder.ExternalParallelTransition().
+		From(StateA).
+		ToAmong(StateC, StateD).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(countingAction)
+
+	sm, err := builder.Build("SimulateTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	result, err := sm.Simulate(StateA, Event1, testPayload{Value: "c"})
+	if err != nil {
+		t.Fatalf("Unexpected simulation error: %v", err)
+	}
+	if result.Target != StateC || !reflect.DeepEqual(result.Targets, []testState{StateC, StateD}) {
+		t.Errorf("Unexpected targets: %s, %v", result.Target, result.Targets)
+	}
+
+	reasons := make([]string, 0, len(result.Considered))
+	for _, evaluation := range result.Considered {
+		reasons = append(reasons, evaluation.Reason)
+	}
+	expected := []string{ReasonConditionNotMet, ReasonSelected, ReasonPrecededByEarlier}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("Expected reasons %v, got %v", expected, reasons)
+	}
+
+	if executed != 0 {
+		t.Errorf("Expected no action to run during simulation, got %d", executed)
+	}
+}
+
+// TestSimulateConditionNotMet tests that Simulate agrees with FireEvent where Verify does not
+func TestSimulateConditionNotMet(t *testing.T) {
+	sm := createQueryStateMachine(t, "SimulateConditionNotMet")
+
+	if !sm.Verify(StateA, Event1) {
+		t.Fatal("Expected Verify to report a transition")
+	}
+
+	result, err := sm.Simulate(StateA, Event1, testPayload{})
+	if !errors.Is(err, ErrConditionNotMet) {
+		t.Errorf("Expected ErrConditionNotMet, got %v", err)
+	}
+	if len(result.Considered) != 1 || result.Considered[0].ConditionMet {
+		t.Errorf("Expected one rejected candidate, got %+v", result.Considered)
+	}
+
+	if _, err := sm.Simulate(StateD, Event1, testPayload{}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Expected ErrStateNotFound, got %v", err)
+	}
+}
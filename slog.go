@@ -0,0 +1,38 @@
+//go:build go1.21
+
+package fsm
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger adapts a *slog.Logger to the Logger interface
+// Parameters:
+//
+//	logger: The slog logger, slog.Default() if nil
+//
+// Returns:
+//
+//	A Logger emitting one slog record per firing
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return LoggerFunc(func(ctx context.Context, record LogRecord) {
+		attrs := []slog.Attr{
+			slog.String("machine", record.MachineId),
+			slog.String("from", record.From),
+			slog.String("event", record.Event),
+			slog.String("outcome", string(record.Outcome)),
+			slog.Duration("duration", record.Duration),
+		}
+		if record.To != "" {
+			attrs = append(attrs, slog.String("to", record.To), slog.String("type", record.Type))
+		}
+		if record.Err != nil {
+			attrs = append(attrs, slog.Any("error", record.Err))
+		}
+		logger.LogAttrs(ctx, slog.Level(record.Level), record.Message, attrs...)
+	})
+}
@@ -0,0 +1,44 @@
+//go:build go1.21
+
+package fsm
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestSlogLogger tests that records are forwarded to log/slog with their attributes
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithLogger(NewSlogLogger(slog.New(handler)))
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(&noopAction{})
+
+	sm, err := builder.Build("SlogTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, _ = sm.FireEvent(StateA, Event1, testPayload{})
+	_, _ = sm.FireEvent(StateC, Event1, testPayload{})
+
+	output := buf.String()
+	for _, expected := range []string{
+		`level=INFO msg="transition completed" machine=SlogTest from=A event=Event1 outcome=success`,
+		"to=B type=External",
+		`level=DEBUG msg="event declined" machine=SlogTest from=C`,
+		`error="state not found"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
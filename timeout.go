@@ -0,0 +1,140 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TransitionError is returned when the action of a transition failed or exceeded its timeout
+// It wraps the error of the action, or ErrActionTimeout, and also matches ErrActionExecutionFailed
+type TransitionError struct {
+	// Err is the reason of the failure, the error of the action or ErrActionTimeout
+	Err error
+	// Timeout is the limit the action exceeded, zero unless Err is ErrActionTimeout
+	Timeout   time.Duration
+	MachineId string
+	Source    string
+	Target    string
+	Event     string
+}
+
+// Error implements error interface
+func (e *TransitionError) Error() string {
+	if e.Err == ErrActionTimeout {
+		return fmt.Sprintf("%v after %v in %s: %s --%s--> %s", e.Err, e.Timeout, e.MachineId, e.Source, e.Event, e.Target)
+	}
+	return fmt.Sprintf("%v in %s: %s --%s--> %s: %v", ErrActionExecutionFailed, e.MachineId, e.Source, e.Event, e.Target, e.Err)
+}
+
+// Is reports whether target is ErrActionExecutionFailed, so callers checking it see every failure of an action
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrActionExecutionFailed
+}
+
+// Unwrap returns the reason of the failure
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// actionTimeout returns the timeout of a transition's action, the machine default unless the transition sets one
+func (sm *StateMachineImpl[S, E, P]) actionTimeout(transition *Transition[S, E, P]) time.Duration {
+	if transition.Timeout > 0 {
+		return transition.Timeout
+	}
+	return sm.defaultTimeout
+}
+
+// attemptOutcome is what the goroutine running a timed action reports
+type attemptOutcome struct {
+	err      error
+	panicked *goroutinePanic
+}
+
+// runAttempt runs one attempt of a transition's action
+// When the action has a timeout it runs in its own goroutine and is abandoned, with its context cancelled,
+// once the timeout elapses; ErrActionTimeout is then returned whatever the action returned, or while it is still running.
+// When the caller's context ends first its error is returned instead. The end of an attempt abandoned still running
+// is reported on run.abandoned
+func (sm *StateMachineImpl[S, E, P]) runAttempt(f *firing[S, E], transition *Transition[S, E, P], payload P, attempt int, run *actionRun) error {
+	run.abandoned = nil
+	timeout := sm.actionTimeout(transition)
+	if timeout <= 0 || transition.Action == nil {
+		return sm.runProtected(f, f.ctx, transition, payload, attempt, run)
+	}
+
+	ctx, cancel := context.WithTimeout(f.ctx, timeout)
+	defer cancel()
+
+	// The abandoned action may still use the value stash, so it must exist before the action starts
+	if f.scope == nil {
+		f.scope = &firingScope[E]{}
+	}
+	done := make(chan attemptOutcome, 1)
+	go func() {
+		var outcome attemptOutcome
+		defer func() { done <- outcome }()
+		defer capturePanic(&outcome.panicked)
+		outcome.err = sm.runProtected(f, ctx, transition, payload, attempt, run)
+	}()
+
+	var outcome attemptOutcome
+	finished := true
+	select {
+	case outcome = <-done:
+	case <-ctx.Done():
+		select {
+		case outcome = <-done:
+		default:
+			finished = false
+			run.abandoned = done
+		}
+	}
+	// A panic of an abandoned action reaches the caller only when a retry waits for the attempt, it is lost otherwise
+	outcome.panicked.raise()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded && f.ctx.Err() == nil:
+		return ErrActionTimeout
+	case !finished:
+		return f.ctx.Err()
+	default:
+		return outcome.err
+	}
+}
+
+// runProtected runs one attempt of a transition's action with panic recovery
+func (sm *StateMachineImpl[S, E, P]) runProtected(f *firing[S, E], ctx context.Context, transition *Transition[S, E, P], payload P, attempt int, run *actionRun) error {
+	var err error
+	if panicErr := sm.protect(PhaseAction, transition, func() {
+		err = sm.execute(f, ctx, transition, payload, attempt, run)
+	}); panicErr != nil {
+		return panicErr
+	}
+	return err
+}
+
+// actionError maps the failure of a transition's action to the error returned from the firing
+// Panics and the end of the caller's context are returned as is, other failures and timeouts
+// as a *TransitionError wrapping the action's error
+func (sm *StateMachineImpl[S, E, P]) actionError(f *firing[S, E], transition *Transition[S, E, P], err error) error {
+	if _, ok := err.(*PanicError); ok {
+		return err
+	}
+	if ctxErr := f.ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	var timeout time.Duration
+	if err == ErrActionTimeout {
+		timeout = sm.actionTimeout(transition)
+	}
+	return &TransitionError{
+		Err:       err,
+		Timeout:   timeout,
+		MachineId: sm.id,
+		Source:    fmt.Sprint(transition.Source.GetID()),
+		Target:    fmt.Sprint(transition.Target.GetID()),
+		Event:     fmt.Sprint(transition.Event),
+	}
+}
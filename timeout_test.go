@@ -0,0 +1,235 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestActionTimeout tests that a context-aware action exceeding its timeout is cancelled and reported
+func TestActionTimeout(t *testing.T) {
+	cancelled := make(chan error, 1)
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				<-tc.Done()
+				cancelled <- tc.Err()
+				return tc.Err()
+			})).
+		Timeout(10 * time.Millisecond)
+
+	sm, err := builder.Build("ActionTimeoutTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	_, err = sm.FireEvent(StateA, Event1, testPayload{})
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("Expected *TransitionError, got %v", err)
+	}
+	if !errors.Is(err, ErrActionTimeout) || !errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected error to match ErrActionTimeout and ErrActionExecutionFailed, got %v", err)
+	}
+	if transitionErr.Timeout != 10*time.Millisecond || transitionErr.Source != "A" || transitionErr.Target != "B" {
+		t.Errorf("Unexpected error details: %+v", transitionErr)
+	}
+
+	select {
+	case err := <-cancelled:
+		if err == nil {
+			t.Error("Expected the action's context to report why it was cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the action's context to be cancelled")
+	}
+}
+
+// TestDefaultActionTimeout tests the machine default and its per-transition override
+func TestDefaultActionTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithActionTimeout(10 * time.Millisecond)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			<-release
+			return nil
+		})
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}).
+		Timeout(time.Second)
+
+	sm, err := builder.Build("DefaultActionTimeoutTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); !errors.Is(err, ErrActionTimeout) {
+		t.Errorf("Expected ErrActionTimeout for a hung action, got %v", err)
+	}
+
+	target, err := sm.FireEvent(StateA, Event2, testPayload{})
+	if err != nil || target != StateC {
+		t.Errorf("Expected C within the transition's own timeout, got %v: %v", target, err)
+	}
+}
+
+// TestActionTimeoutRetry tests that timed out attempts are retried
+func TestActionTimeoutRetry(t *testing.T) {
+	var attempts int32
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		When(&alwaysTrueCondition{}).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				atomic.AddInt32(&attempts, 1)
+				if tc.Attempt == 1 {
+					<-tc.Done()
+				}
+				return nil
+			})).
+		Timeout(10 * time.Millisecond).
+		Retry(RetryPolicy{
+			MaxAttempts: 2,
+			RetryIf: func(err error) bool {
+				return errors.Is(err, ErrActionTimeout)
+			},
+		})
+
+	sm, err := builder.Build("ActionTimeoutRetryTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	target, err := sm.FireEvent(StateA, Event1, testPayload{})
+	if err != nil || target != StateB || atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Expected B on the second attempt, got %v after %d attempts: %v", target, attempts, err)
+	}
+}
+
+// TestActionTimeoutCallerCancellation tests that the end of the caller's context is reported as is
+func TestActionTimeoutCallerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				cancel()
+				<-tc.Done()
+				return tc.Err()
+			})).
+		Timeout(time.Second)
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event1).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				return tc.Err()
+			}))
+
+	sm, err := builder.Build("ActionTimeoutCancellationTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEventContext(ctx, StateA, Event1, testPayload{}); !errors.Is(err, context.Canceled) || errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected context.Canceled for a timed action, got %v", err)
+	}
+	if _, err := sm.FireEventContext(ctx, StateB, Event1, testPayload{}); !errors.Is(err, context.Canceled) || errors.Is(err, ErrActionExecutionFailed) {
+		t.Errorf("Expected context.Canceled for an untimed action, got %v", err)
+	}
+}
+
+// TestActionTimeoutPanicReachesCaller tests that a panic of a timed action without panic recovery can be recovered by the caller
+func TestActionTimeoutPanicReachesCaller(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithActionTimeout(time.Second)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			panic("action exploded")
+		})
+
+	sm, err := builder.Build("ActionTimeoutPanicTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	recovered := func() (value interface{}) {
+		defer func() {
+			value = recover()
+		}()
+		sm.FireEvent(StateA, Event1, testPayload{})
+		return nil
+	}()
+	if recovered != "action exploded" {
+		t.Errorf("Expected the action panic on the calling goroutine, got %v", recovered)
+	}
+}
+
+// TestActionTimeoutRetryWaitsForAbandonedAttempt tests that a timed out plain action is not retried while it still runs
+func TestActionTimeoutRetryWaitsForAbandonedAttempt(t *testing.T) {
+	var running, overlapping, attempts int32
+
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]()
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			atomic.AddInt32(&attempts, 1)
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.StoreInt32(&overlapping, 1)
+			}
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}).
+		Timeout(10 * time.Millisecond).
+		Retry(RetryPolicy{MaxAttempts: 3})
+
+	sm, err := builder.Build("ActionTimeoutRetryWaitTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); !errors.Is(err, ErrActionTimeout) {
+		t.Errorf("Expected ErrActionTimeout, got %v", err)
+	}
+	if atomic.LoadInt32(&overlapping) != 0 {
+		t.Error("Expected attempts of the same action not to overlap")
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,181 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Span names used by state machines
+const (
+	SpanFireEvent         = "fsm.FireEvent"
+	SpanFireParallelEvent = "fsm.FireParallelEvent"
+	SpanCondition         = "fsm.condition"
+	SpanAction            = "fsm.action"
+)
+
+// Attribute keys used by state machines
+const (
+	AttributeMachineId = "fsm.machine_id"
+	AttributeSource    = "fsm.source"
+	AttributeEvent     = "fsm.event"
+	AttributeTarget    = "fsm.target"
+	AttributeOutcome   = "fsm.outcome"
+	AttributeSatisfied = "fsm.condition.satisfied"
+	AttributeCondition = "fsm.condition.name"
+	AttributeAttempts  = "fsm.action.attempts"
+)
+
+// Attribute is a key-value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates a span attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans, its shape follows OpenTelemetry's trace.Tracer so an adapter is a few lines
+type Tracer interface {
+	// Start opens a span as a child of the span carried by ctx, if any,
+	// and returns a context carrying the new span
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is a unit of traced work, its shape follows OpenTelemetry's trace.Span
+type Span interface {
+	// SetAttributes adds or overwrites attributes of the span
+	SetAttributes(attributes ...Attribute)
+
+	// RecordError marks the span as failed with the given error
+	RecordError(err error)
+
+	// End completes the span
+	End()
+}
+
+// RecordingTracer keeps every span in memory, intended for tests
+type RecordingTracer struct {
+	spans []*RecordedSpan
+	mutex sync.Mutex
+}
+
+// RecordedSpan is a span kept by RecordingTracer
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]interface{}
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+	Ended      bool
+	tracer     *RecordingTracer
+}
+
+// recordedSpanKey is the context key under which RecordingTracer stores the current span
+type recordedSpanKey struct{}
+
+// NewRecordingTracer creates a tracer that records spans in memory
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// Start implements Tracer interface
+func (t *RecordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanKey{}).(*RecordedSpan)
+	span := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: make(map[string]interface{}),
+		StartTime:  time.Now(),
+		tracer:     t,
+	}
+	span.SetAttributes(attributes...)
+
+	t.mutex.Lock()
+	t.spans = append(t.spans, span)
+	t.mutex.Unlock()
+
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+// Spans returns the recorded spans in the order they were started
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Reset discards all recorded spans
+func (t *RecordingTracer) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.spans = nil
+}
+
+// SetAttributes implements Span interface
+func (s *RecordedSpan) SetAttributes(attributes ...Attribute) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	for _, attribute := range attributes {
+		s.Attributes[attribute.Key] = attribute.Value
+	}
+}
+
+// RecordError implements Span interface
+func (s *RecordedSpan) RecordError(err error) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+// End implements Span interface
+func (s *RecordedSpan) End() {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	s.EndTime = time.Now()
+	s.Ended = true
+}
+
+// Duration returns how long the span lasted, zero while it has not ended
+func (s *RecordedSpan) Duration() time.Duration {
+	if !s.Ended {
+		return 0
+	}
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// startSpan opens a span on the machine's tracer with the machine id, source and event attributes
+// The returned span is nil when no tracer is configured
+func (sm *StateMachineImpl[S, E, P]) startSpan(ctx context.Context, name string, source S, event E, attributes ...Attribute) (context.Context, Span) {
+	if sm.tracer == nil {
+		return ctx, nil
+	}
+	attributes = append([]Attribute{
+		Attr(AttributeMachineId, sm.id),
+		Attr(AttributeSource, fmt.Sprint(source)),
+		Attr(AttributeEvent, fmt.Sprint(event)),
+	}, attributes...)
+	return sm.tracer.Start(ctx, name, attributes...)
+}
+
+// endFireSpan records the outcome of a firing on its span and ends it
+func endFireSpan[S any](span Span, targets []S, err error) {
+	span.SetAttributes(Attr(AttributeOutcome, string(outcomeOf(err))))
+	if err != nil {
+		span.RecordError(err)
+	} else if len(targets) == 1 {
+		span.SetAttributes(Attr(AttributeTarget, fmt.Sprint(targets[0])))
+	} else {
+		span.SetAttributes(Attr(AttributeTarget, fmt.Sprint(targets)))
+	}
+	span.End()
+}
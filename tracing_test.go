@@ -0,0 +1,113 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestTracingSpans tests that a firing opens a span with child spans for conditions and actions
+func TestTracingSpans(t *testing.T) {
+	tracer := NewRecordingTracer()
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithTracer(tracer)
+
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		WhenFunc(func(payload testPayload) bool {
+			return payload.Value == "ok"
+		}).
+		Perform(&noopAction{})
+	gatewayDown := errors.New("gateway down")
+	builder.ExternalTransition().
+		From(StateB).
+		To(StateC).
+		On(Event2).
+		When(&alwaysTrueCondition{}).
+		PerformFunc(func(from, to testState, event testEvent, payload testPayload) error {
+			return gatewayDown
+		})
+
+	sm, err := builder.Build("TracingTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+
+	ctx, request := tracer.Start(context.Background(), "request")
+	if _, err := sm.FireEventContext(ctx, StateA, Event1, testPayload{Value: "ok"}); err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+	request.End()
+
+	spans := tracer.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	fire, condition, action := spans[1], spans[2], spans[3]
+
+	if fire.Name != SpanFireEvent || fire.Parent != spans[0] {
+		t.Errorf("Expected %s span under the request span, got %s", SpanFireEvent, fire.Name)
+	}
+	if fire.Attributes[AttributeMachineId] != "TracingTest" || fire.Attributes[AttributeSource] != "A" ||
+		fire.Attributes[AttributeEvent] != "Event1" || fire.Attributes[AttributeTarget] != "B" ||
+		fire.Attributes[AttributeOutcome] != "success" {
+		t.Errorf("Unexpected firing attributes: %v", fire.Attributes)
+	}
+	if condition.Name != SpanCondition || condition.Parent != fire || condition.Attributes[AttributeSatisfied] != true {
+		t.Errorf("Unexpected condition span: %s %v", condition.Name, condition.Attributes)
+	}
+	if action.Name != SpanAction || action.Parent != fire || !action.Ended {
+		t.Errorf("Unexpected action span: %s", action.Name)
+	}
+
+	tracer.Reset()
+	if _, err := sm.FireEvent(StateB, Event2, testPayload{}); err == nil {
+		t.Fatal("Expected action error")
+	}
+
+	spans = tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	for _, span := range []*RecordedSpan{spans[0], spans[2]} {
+		if len(span.Errors) != 1 || !errors.Is(span.Errors[0], ErrActionExecutionFailed) || !errors.Is(span.Errors[0], gatewayDown) {
+			t.Errorf("Expected %s span to record the action error, got %v", span.Name, span.Errors)
+		}
+	}
+	if spans[0].Attributes[AttributeOutcome] != "error" {
+		t.Errorf("Expected error outcome, got %v", spans[0].Attributes[AttributeOutcome])
+	}
+}
+
+// TestTracingActionContext tests that spans started by contextual actions are children of the action span
+func TestTracingActionContext(t *testing.T) {
+	tracer := NewRecordingTracer()
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithTracer(tracer)
+	builder.ExternalTransition().
+		From(StateA).
+		To(StateB).
+		On(Event1).
+		Perform(ContextualActionFunc[testState, testEvent, testPayload](
+			func(tc *TransitionContext[testState, testEvent], payload testPayload) error {
+				_, span := tracer.Start(tc, "charge")
+				span.End()
+				return nil
+			}))
+
+	sm, err := builder.Build("TracingActionContextTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil {
+		t.Fatalf("Failed to fire event: %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	if action, charge := spans[1], spans[2]; action.Name != SpanAction || charge.Name != "charge" || charge.Parent != action {
+		t.Errorf("Expected the span of the action to be the parent of its own spans, got %s under %v", charge.Name, charge.Parent)
+	}
+}
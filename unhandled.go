@@ -0,0 +1,50 @@
+package fsm
+
+// UnhandledEventPolicy decides what firing an event does when the source state declares no transition for it
+// Events whose conditions are not met are not affected, they always fail with ErrConditionNotMet
+type UnhandledEventPolicy int
+
+const (
+	// UnhandledEventError fails the firing with ErrTransitionNotFound, the default
+	UnhandledEventError UnhandledEventPolicy = iota
+	// UnhandledEventIgnore keeps the machine in the source state without error
+	UnhandledEventIgnore
+	// UnhandledEventFallback passes the event to the fallback handler, the machine stays in the source state
+	// Building a machine with this policy and no handler fails with ErrMissingFallbackHandler
+	UnhandledEventFallback
+)
+
+// UnhandledEventHandler receives the events no transition is declared for under UnhandledEventFallback
+// Its error is returned from the firing call, nil keeps the machine in the source state without error
+type UnhandledEventHandler[S comparable, E comparable, P any] func(state S, event E, payload P) error
+
+// ignore marks events as expected in this state although no transition is declared for them
+func (s *State[S, E, P]) ignore(events ...E) {
+	if s.ignored == nil {
+		s.ignored = make(map[E]bool)
+	}
+	for _, event := range events {
+		s.ignored[event] = true
+	}
+}
+
+// unhandled applies the per-state ignore lists and the unhandled event policy to an event without transitions
+// It returns the source state as the only target when the event is ignored or handled by the fallback handler
+func (sm *StateMachineImpl[S, E, P]) unhandled(f *firing[S, E], listeners []Listener[S, E, P], state *State[S, E, P], event E, payload P) ([]S, error) {
+	switch {
+	case state.ignored[event], sm.unhandledEvents == UnhandledEventIgnore:
+	case sm.unhandledEvents == UnhandledEventFallback:
+		if err := sm.onUnhandled(state.GetID(), event, payload); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, sm.decline(listeners, state.GetID(), event, payload, ErrTransitionNotFound)
+	}
+
+	if f.result != nil {
+		f.result.Target = state.GetID()
+		f.result.Targets = []S{state.GetID()}
+		f.result.Ignored = true
+	}
+	return []S{state.GetID()}, nil
+}
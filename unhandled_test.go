@@ -0,0 +1,113 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestUnhandledEventPolicy tests the error, ignore and fallback policies for events without transitions
+func TestUnhandledEventPolicy(t *testing.T) {
+	build := func(id string, policy UnhandledEventPolicy, handler ...UnhandledEventHandler[testState, testEvent, testPayload]) StateMachine[testState, testEvent, testPayload] {
+		builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithUnhandledEventPolicy(policy, handler...)
+		builder.ExternalTransition().From(StateA).To(StateB).On(Event1).
+			WhenFunc(func(payload testPayload) bool {
+				return payload.Value != "blocked"
+			}).
+			Perform(&noopAction{})
+		sm, err := builder.Build(id)
+		if err != nil {
+			t.Fatalf("Failed to build state machine: %v", err)
+		}
+		return sm
+	}
+
+	sm := build("UnhandledErrorTest", UnhandledEventError)
+	if _, err := sm.FireEvent(StateA, Event2, testPayload{}); !errors.Is(err, ErrTransitionNotFound) {
+		t.Errorf("Expected ErrTransitionNotFound by default, got %v", err)
+	}
+
+	sm = build("UnhandledIgnoreTest", UnhandledEventIgnore)
+	if state, err := sm.FireEvent(StateA, Event2, testPayload{}); err != nil || state != StateA {
+		t.Errorf("Expected the event to be ignored, got %v %v", state, err)
+	}
+	if _, err := sm.FireEvent(StateA, Event1, testPayload{Value: "blocked"}); !errors.Is(err, ErrConditionNotMet) {
+		t.Errorf("Expected unmet conditions to fail, got %v", err)
+	}
+	if _, err := sm.FireEvent(StateD, Event2, testPayload{}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("Expected unknown states to fail, got %v", err)
+	}
+	result, err := sm.Fire(StateB, Event1, testPayload{})
+	if err != nil || !result.Ignored || result.Target != StateB || len(result.Transitions) != 0 {
+		t.Errorf("Expected the result to report the ignored event, got %+v %v", result, err)
+	}
+
+	var handled []testEvent
+	errRejected := errors.New("rejected")
+	sm = build("UnhandledFallbackTest", UnhandledEventFallback, func(state testState, event testEvent, payload testPayload) error {
+		handled = append(handled, event)
+		if payload.Value == "reject" {
+			return errRejected
+		}
+		return nil
+	})
+	if state, err := sm.FireEvent(StateA, Event2, testPayload{}); err != nil || state != StateA {
+		t.Errorf("Expected the fallback handler to keep the source state, got %v %v", state, err)
+	}
+	if _, err := sm.FireEvent(StateB, Event3, testPayload{Value: "reject"}); !errors.Is(err, errRejected) {
+		t.Errorf("Expected the error of the fallback handler, got %v", err)
+	}
+	if state, err := sm.FireEvent(StateA, Event1, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected declared transitions to be taken, got %v %v", state, err)
+	}
+	if !reflect.DeepEqual(handled, []testEvent{Event2, Event3}) {
+		t.Errorf("Expected the fallback handler to receive the unhandled events, got %v", handled)
+	}
+}
+
+// TestUnhandledEventFallbackWithoutHandler tests that the fallback policy is rejected without a handler
+func TestUnhandledEventFallbackWithoutHandler(t *testing.T) {
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().WithUnhandledEventPolicy(UnhandledEventFallback)
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).Perform(&noopAction{})
+
+	if _, err := builder.Build("UnhandledFallbackWithoutHandlerTest"); !errors.Is(err, ErrMissingFallbackHandler) {
+		t.Errorf("Expected ErrMissingFallbackHandler, got %v", err)
+	}
+	if _, err := GetStateMachine[testState, testEvent, testPayload]("UnhandledFallbackWithoutHandlerTest"); !errors.Is(err, ErrStateMachineNotFound) {
+		t.Errorf("Expected the rejected machine not to be registered, got %v", err)
+	}
+}
+
+// TestIgnoreEvents tests that per-state ignore lists absorb events without transitions
+func TestIgnoreEvents(t *testing.T) {
+	var declined []error
+	builder := NewStateMachineBuilder[testState, testEvent, testPayload]().Ignore(StateB, Event1, Event2)
+	builder.ExternalTransition().From(StateA).To(StateB).On(Event1).Perform(&noopAction{})
+	builder.ExternalTransition().From(StateB).To(StateC).On(Event2).Perform(&noopAction{})
+
+	sm, err := builder.Build("IgnoreEventsTest")
+	if err != nil {
+		t.Fatalf("Failed to build state machine: %v", err)
+	}
+	sm.AddListener(ListenerFuncs[testState, testEvent, testPayload]{
+		Declined: func(from testState, event testEvent, payload testPayload, reason error) {
+			declined = append(declined, reason)
+		},
+	})
+
+	if state, err := sm.FireEvent(StateB, Event1, testPayload{}); err != nil || state != StateB {
+		t.Errorf("Expected the duplicate event to be ignored, got %v %v", state, err)
+	}
+	if state, err := sm.FireEvent(StateB, Event2, testPayload{}); err != nil || state != StateC {
+		t.Errorf("Expected declared transitions to win over the ignore list, got %v %v", state, err)
+	}
+	if _, err := sm.FireEvent(StateB, Event3, testPayload{}); !errors.Is(err, ErrTransitionNotFound) {
+		t.Errorf("Expected events outside the ignore list to fail, got %v", err)
+	}
+	if _, err := sm.FireEvent(StateA, Event2, testPayload{}); !errors.Is(err, ErrTransitionNotFound) {
+		t.Errorf("Expected other states to fail, got %v", err)
+	}
+	if len(declined) != 2 {
+		t.Errorf("Expected listeners to see only the failing events declined, got %v", declined)
+	}
+}